@@ -34,6 +34,10 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 
 	comment, err := h.commentUsecase.CreateComment(ctx, blogID, userID, input.Message)
 	if err != nil {
+		if err.Error() == "blog not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -41,13 +45,86 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 	c.JSON(http.StatusCreated, comment)
 }
 
+func (h *CommentHandler) CreateReply(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	blogID := c.Param("blogId")
+	parentID := c.Param("id")
+	userID := c.MustGet("userID").(string)
+
+	var input struct {
+		Message string `json:"message" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	reply, err := h.commentUsecase.CreateReply(ctx, blogID, parentID, userID, input.Message)
+	if err != nil {
+		switch err.Error() {
+		case "comment not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		case "blog not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+		case "maximum reply depth reached":
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, reply)
+}
+
+func (h *CommentHandler) GetReplies(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	blogID := c.Param("blogId")
+	parentID := c.Param("id")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	sort := c.DefaultQuery("sort", "oldest")
+	viewerID := c.GetString("userID")
+
+	replies, total, err := h.commentUsecase.GetReplies(ctx, blogID, parentID, viewerID, page, limit, sort)
+	if err != nil {
+		if err.Error() == "blog not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  replies,
+		"total": total,
+	})
+}
+
 func (h *CommentHandler) GetCommentByID(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	blogID := c.Param("blogId")
 	commentID := c.Param("id")
 
-	comment, err := h.commentUsecase.GetCommentByID(ctx, blogID, commentID)
+	viewerID := c.GetString("userID")
+
+	comment, err := h.commentUsecase.GetCommentByID(ctx, blogID, commentID, viewerID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
 		return
@@ -74,9 +151,14 @@ func (h *CommentHandler) GetAllComments(c *gin.Context) {
 	}
 
 	sort := c.DefaultQuery("sort", "latest")
+	viewerID := c.GetString("userID")
 
-	comments, total, err := h.commentUsecase.GetAllComments(ctx, blogID, page, limit, sort)
+	comments, total, err := h.commentUsecase.GetAllComments(ctx, blogID, viewerID, page, limit, sort)
 	if err != nil {
+		if err.Error() == "blog not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -149,3 +231,70 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
 }
+
+// GetModerationQueue lists pending comments on the caller's blogs, or on every blog for admins
+func (h *CommentHandler) GetModerationQueue(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	var comments []*domain.Comment
+	var total int
+	if role, _ := c.Get("role"); role == "admin" {
+		comments, total, err = h.commentUsecase.GetModerationQueueAsAdmin(ctx, page, limit)
+	} else {
+		comments, total, err = h.commentUsecase.GetModerationQueue(ctx, c.GetString("userID"), page, limit)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  comments,
+		"total": total,
+	})
+}
+
+// ModerateComments approves or rejects pending comments in bulk
+func (h *CommentHandler) ModerateComments(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input domain.ModerationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "comment_ids and an action of approve or reject are required"})
+		return
+	}
+
+	var moderated int
+	var err error
+	if role, _ := c.Get("role"); role == "admin" {
+		moderated, err = h.commentUsecase.ModerateCommentsAsAdmin(ctx, input)
+	} else {
+		moderated, err = h.commentUsecase.ModerateComments(ctx, c.GetString("userID"), input)
+	}
+	if err != nil {
+		switch err.Error() {
+		case "invalid comment ID", "invalid moderation action":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Comments moderated successfully",
+		"moderated": moderated,
+	})
+}
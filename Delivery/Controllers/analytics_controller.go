@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsController struct {
+	analyticsUsecase domain.AnalyticsUsecase
+}
+
+func NewAnalyticsController(au domain.AnalyticsUsecase) *AnalyticsController {
+	return &AnalyticsController{analyticsUsecase: au}
+}
+
+// GetBlogAnalytics returns a blog's activity over time; only its author and admins can see it
+func (ac *AnalyticsController) GetBlogAnalytics(c *gin.Context) {
+	query, ok := analyticsQueryFrom(c)
+	if !ok {
+		return
+	}
+
+	var (
+		analytics *domain.BlogAnalytics
+		err       error
+	)
+	if c.GetString("role") == "admin" {
+		analytics, err = ac.analyticsUsecase.GetBlogAnalyticsAsAdmin(c.Request.Context(), c.Param("id"), query)
+	} else {
+		analytics, err = ac.analyticsUsecase.GetBlogAnalytics(c.Request.Context(), c.Param("id"), c.GetString("userID"), query)
+	}
+	if err != nil {
+		writeAnalyticsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// GetDashboard returns the activity of all of the requesting author's blogs
+func (ac *AnalyticsController) GetDashboard(c *gin.Context) {
+	query, ok := analyticsQueryFrom(c)
+	if !ok {
+		return
+	}
+
+	dashboard, err := ac.analyticsUsecase.GetAuthorDashboard(c.Request.Context(), c.GetString("userID"), query)
+	if err != nil {
+		writeAnalyticsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// analyticsQueryFrom reads from, to and granularity; dates are RFC 3339 timestamps or YYYY-MM-DD days,
+// and a day given as to is included in the period
+func analyticsQueryFrom(c *gin.Context) (domain.AnalyticsQuery, bool) {
+	query := domain.AnalyticsQuery{Granularity: c.Query("granularity")}
+
+	from, err := parseAnalyticsTime(c.Query("from"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected RFC3339 or YYYY-MM-DD"})
+		return query, false
+	}
+	to, err := parseAnalyticsTime(c.Query("to"), true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, expected RFC3339 or YYYY-MM-DD"})
+		return query, false
+	}
+
+	query.From, query.To = from, to
+	return query, true
+}
+
+func parseAnalyticsTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24 * time.Hour)
+	}
+	return t, nil
+}
+
+func writeAnalyticsError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "blog not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "unauthorized access":
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view analytics of your own blog"})
+	case "invalid granularity", "from must be before to", "date range too large for granularity":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
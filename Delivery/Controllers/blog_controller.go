@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -40,6 +41,10 @@ func (h *BlogHandler) UpdateBlog(c *gin.Context) {
 	}
 
 	if err := h.blogUsecase.UpdateBlog(c.Request.Context(), id, userIDStr, input); err != nil {
+		if errors.Is(err, domain.ErrRevisionConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		switch err.Error() {
 		case "blog not found":
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
@@ -56,6 +61,46 @@ func (h *BlogHandler) UpdateBlog(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Blog updated successfully"})
 }
 
+func (h *BlogHandler) UpdateBlogStatus(c *gin.Context) {
+	id := c.Param("id")
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input domain.BlogStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status is required"})
+		return
+	}
+
+	var err error
+	role, _ := c.Get("role")
+	if role == "admin" {
+		err = h.blogUsecase.UpdateBlogStatusAsAdmin(c.Request.Context(), id, input)
+	} else {
+		err = h.blogUsecase.UpdateBlogStatus(c.Request.Context(), id, userID.(string), input)
+	}
+
+	if err != nil {
+		switch err.Error() {
+		case "blog not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+		case "unauthorized access":
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only change the status of your own blog"})
+		case "invalid status", "invalid status transition", "publish_at must be in the future":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blog status updated successfully", "status": input.Status})
+}
+
 func (bc *BlogHandler) DeleteBlog(c *gin.Context) {
 	blogID := c.Param("id")
 
@@ -93,6 +138,20 @@ func (bc *BlogHandler) DeleteBlog(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Blog deleted successfully"})
 }
 
+// RecomputeScores starts rescoring every blog in the background; only one recompute runs at a time
+func (h *BlogHandler) RecomputeScores(c *gin.Context) {
+	if err := h.blogUsecase.StartScoreRecompute(); err != nil {
+		if err.Error() == "score recompute already running" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Score recompute started"})
+}
+
 func (h *BlogHandler) GetAllBlogs(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -112,8 +171,50 @@ func (h *BlogHandler) GetAllBlogs(c *gin.Context) {
 		return
 	}
 
-	result, err := h.blogUsecase.GetAllBlogs(ctx, page, limit, sort)
+	result, err := h.blogUsecase.GetAllBlogs(ctx, page, limit, sort, c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *BlogHandler) GetMyBlogs(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, ok := userIDVal.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID is not a string"})
+		return
+	}
+
+	status := strings.TrimSpace(c.Query("status"))
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	result, err := h.blogUsecase.GetMyBlogs(ctx, userID, status, page, limit)
 	if err != nil {
+		if err.Error() == "invalid status" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -125,13 +226,70 @@ func (h *BlogHandler) GetBlogById(c *gin.Context) {
 	ctx := c.Request.Context()
 	blogID := c.Param("id")
 
-	blog, err := h.blogUsecase.ViewBlog(ctx, blogID)
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != "html" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or html"})
+		return
+	}
+
+	blog, err := h.blogUsecase.ViewBlog(ctx, blogID, viewerFrom(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, blogInFormat(blog, format))
+}
+
+// viewerFrom describes who is viewing a blog; the user ID is set only when the optional login middleware found a valid token
+func viewerFrom(c *gin.Context) domain.Viewer {
+	return domain.Viewer{
+		UserID:    c.GetString("userID"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// blogInFormat returns a copy of the blog holding only the Markdown source or only the rendered HTML
+func blogInFormat(blog *domain.Blog, format string) domain.Blog {
+	formatted := *blog
+	if format == "html" {
+		formatted.Content = ""
+	} else {
+		formatted.ContentHTML = ""
+	}
+	return formatted
+}
+
+func (h *BlogHandler) GetBlogBySlug(c *gin.Context) {
+	ctx := c.Request.Context()
+	slug := c.Param("slug")
+
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != "html" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or html"})
+		return
+	}
+
+	blogID, currentSlug, err := h.blogUsecase.ResolveSlug(ctx, slug)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// old slugs of a renamed blog redirect to its current slug
+	if currentSlug != slug {
+		c.Redirect(http.StatusMovedPermanently, "/blogs/by-slug/"+currentSlug+"?format="+format)
+		return
+	}
+
+	blog, err := h.blogUsecase.ViewBlog(ctx, blogID, viewerFrom(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, blog)
+	c.JSON(http.StatusOK, blogInFormat(blog, format))
 }
 
 func (h *BlogHandler) CreateBlog(c *gin.Context) {
@@ -212,6 +370,73 @@ func (h *BlogHandler) DislikeBlog(c *gin.Context) {
 
 }
 
+func (h *BlogHandler) ReactToBlog(c *gin.Context) {
+	var input domain.ReactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type is required"})
+		return
+	}
+
+	reaction, err := h.blogUsecase.ReactToBlog(c.Request.Context(), c.Param("id"), c.GetString("userID"), input.Type)
+	if err != nil {
+		writeReactionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reaction)
+}
+
+func (h *BlogHandler) RemoveReaction(c *gin.Context) {
+	reactionType := c.Query("type")
+	if reactionType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type is required"})
+		return
+	}
+
+	if err := h.blogUsecase.RemoveReaction(c.Request.Context(), c.Param("id"), c.GetString("userID"), reactionType); err != nil {
+		writeReactionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "reaction removed successfully"})
+}
+
+// GetReactions lists the users who reacted to a blog, optionally filtered by reaction type
+func (h *BlogHandler) GetReactions(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	reactions, err := h.blogUsecase.GetReactions(c.Request.Context(), c.Param("id"), c.Query("type"), page, limit)
+	if err != nil {
+		writeReactionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reactions)
+}
+
+func writeReactionError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "blog not found", "reaction not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "already reacted":
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reacted with this reaction"})
+	case "invalid reaction", "invalid pagination params":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
+
 func (h *BlogHandler) FilterBlogs(c *gin.Context) {
 	ctx := c.Request.Context()
 	rawTags := c.QueryArray("tags")
@@ -305,3 +530,78 @@ func (h *BlogHandler) SearchBlogs(c *gin.Context) {
 		"blogs": blogs,
 	})
 }
+
+func (h *BlogHandler) GetRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+	blogID := c.Param("id")
+	userID := c.GetString("userID")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	result, err := h.blogUsecase.GetRevisions(ctx, blogID, userID, page, limit)
+	if err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *BlogHandler) GetRevisionDiff(c *gin.Context) {
+	ctx := c.Request.Context()
+	blogID := c.Param("id")
+	userID := c.GetString("userID")
+
+	// either revision can be "current" to compare against the live blog
+	from := c.Query("from")
+	to := c.DefaultQuery("to", "current")
+
+	diff, err := h.blogUsecase.GetRevisionDiff(ctx, blogID, userID, from, to)
+	if err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *BlogHandler) RestoreRevision(c *gin.Context) {
+	ctx := c.Request.Context()
+	blogID := c.Param("id")
+	revisionID := c.Param("revisionId")
+	userID := c.GetString("userID")
+
+	if err := h.blogUsecase.RestoreRevision(ctx, blogID, userID, revisionID); err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Revision restored successfully"})
+}
+
+func writeRevisionError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrRevisionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	switch err.Error() {
+	case "blog not found", "revision not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "unauthorized access":
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only access revisions of your own blog"})
+	case "from and to revisions are required", "invalid pagination params":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkHandler struct {
+	bookmarkUsecase domain.BookmarkUsecase
+}
+
+func NewBookmarkHandler(bookmarkUsecase domain.BookmarkUsecase) *BookmarkHandler {
+	return &BookmarkHandler{bookmarkUsecase: bookmarkUsecase}
+}
+
+func (h *BookmarkHandler) AddBookmark(c *gin.Context) {
+	var input domain.BookmarkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "blog_id is required"})
+		return
+	}
+
+	bookmark, err := h.bookmarkUsecase.AddBookmark(c.Request.Context(), c.GetString("userID"), input)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, bookmark)
+}
+
+// GetBookmarks lists the user's bookmarks, optionally of a single reading list or only the unread ones
+func (h *BookmarkHandler) GetBookmarks(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	bookmarks, err := h.bookmarkUsecase.GetBookmarks(c.Request.Context(), c.GetString("userID"), c.Query("list_id"), unreadOnly, page, limit)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmarks)
+}
+
+func (h *BookmarkHandler) UpdateBookmark(c *gin.Context) {
+	var input domain.BookmarkUpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.bookmarkUsecase.UpdateBookmark(c.Request.Context(), c.GetString("userID"), c.Param("id"), input); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bookmark updated successfully"})
+}
+
+func (h *BookmarkHandler) RemoveBookmark(c *gin.Context) {
+	if err := h.bookmarkUsecase.RemoveBookmark(c.Request.Context(), c.GetString("userID"), c.Param("id")); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bookmark removed successfully"})
+}
+
+func (h *BookmarkHandler) CreateReadingList(c *gin.Context) {
+	var input domain.ReadingListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required and must be at most 100 characters"})
+		return
+	}
+
+	list, err := h.bookmarkUsecase.CreateReadingList(c.Request.Context(), c.GetString("userID"), input)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+func (h *BookmarkHandler) GetReadingLists(c *gin.Context) {
+	lists, err := h.bookmarkUsecase.GetReadingLists(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": lists})
+}
+
+func (h *BookmarkHandler) UpdateReadingList(c *gin.Context) {
+	var input domain.ReadingListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required and must be at most 100 characters"})
+		return
+	}
+
+	if err := h.bookmarkUsecase.UpdateReadingList(c.Request.Context(), c.GetString("userID"), c.Param("id"), input); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading list updated successfully"})
+}
+
+func (h *BookmarkHandler) DeleteReadingList(c *gin.Context) {
+	if err := h.bookmarkUsecase.DeleteReadingList(c.Request.Context(), c.GetString("userID"), c.Param("id")); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading list deleted successfully"})
+}
+
+func (h *BookmarkHandler) ReorderReadingList(c *gin.Context) {
+	var input domain.ReorderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bookmark_ids is required"})
+		return
+	}
+
+	if err := h.bookmarkUsecase.ReorderReadingList(c.Request.Context(), c.GetString("userID"), c.Param("id"), input); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading list reordered successfully"})
+}
+
+func (h *BookmarkHandler) ShareReadingList(c *gin.Context) {
+	list, err := h.bookmarkUsecase.ShareReadingList(c.Request.Context(), c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *BookmarkHandler) UnshareReadingList(c *gin.Context) {
+	if err := h.bookmarkUsecase.UnshareReadingList(c.Request.Context(), c.GetString("userID"), c.Param("id")); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading list is no longer shared"})
+}
+
+// GetSharedReadingList serves a reading list through its public share link
+func (h *BookmarkHandler) GetSharedReadingList(c *gin.Context) {
+	list, bookmarks, err := h.bookmarkUsecase.GetSharedReadingList(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"list":      list,
+		"bookmarks": bookmarks,
+	})
+}
+
+func writeBookmarkError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "blog not found", "bookmark not found", "reading list not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "blog already bookmarked", "reading list already exists":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "invalid pagination params", "nothing to update", "name cannot be empty", "bookmark ids must match the reading list":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
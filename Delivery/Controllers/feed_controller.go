@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/gin-gonic/gin"
+)
+
+type FeedController struct {
+	feedUsecase domain.FeedUsecase
+}
+
+func NewFeedController(fu domain.FeedUsecase) *FeedController {
+	return &FeedController{feedUsecase: fu}
+}
+
+func (fc *FeedController) RSS(c *gin.Context) {
+	feed, err := fc.feedUsecase.SiteFeed(c.Request.Context())
+	if err != nil {
+		writeFeedError(c, err)
+		return
+	}
+	writeFeed(c, feed, "rss")
+}
+
+func (fc *FeedController) Atom(c *gin.Context) {
+	feed, err := fc.feedUsecase.SiteFeed(c.Request.Context())
+	if err != nil {
+		writeFeedError(c, err)
+		return
+	}
+	writeFeed(c, feed, "atom")
+}
+
+func (fc *FeedController) AuthorFeed(c *gin.Context) {
+	format := c.DefaultQuery("format", "rss")
+	if format != "rss" && format != "atom" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be rss or atom"})
+		return
+	}
+
+	feed, err := fc.feedUsecase.AuthorFeed(c.Request.Context(), c.Param("userId"))
+	if err != nil {
+		writeFeedError(c, err)
+		return
+	}
+	writeFeed(c, feed, format)
+}
+
+func (fc *FeedController) TagFeed(c *gin.Context) {
+	format := c.DefaultQuery("format", "rss")
+	if format != "rss" && format != "atom" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be rss or atom"})
+		return
+	}
+
+	feed, err := fc.feedUsecase.TagFeed(c.Request.Context(), c.Param("tag"))
+	if err != nil {
+		writeFeedError(c, err)
+		return
+	}
+	writeFeed(c, feed, format)
+}
+
+func writeFeedError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "author not found"})
+	case err.Error() == "tag is required":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build feed"})
+	}
+}
+
+// writeFeed renders the feed and answers conditional GETs with 304 Not Modified
+func writeFeed(c *gin.Context, feed *domain.Feed, format string) {
+	var doc any
+	contentType := "application/rss+xml; charset=utf-8"
+	if format == "atom" {
+		doc = toAtom(feed)
+		contentType = "application/atom+xml; charset=utf-8"
+	} else {
+		doc = toRSS(feed)
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render feed"})
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	sum := sha1.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	// the build time is used because the newest blog's update time goes back when that blog is removed
+	if !feed.Modified.IsZero() {
+		c.Header("Last-Modified", feed.Modified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c, etag, feed.Modified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModified reports whether the client's cached copy is still fresh.
+// If-None-Match takes precedence over If-Modified-Since.
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		return etagMatches(match, etag)
+	}
+	if since := c.GetHeader("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match list names the etag.
+// The comparison is weak, so W/"x" matches "x".
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+func toRSS(feed *domain.Feed) rssDoc {
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        feed.Link,
+		Description: feed.Description,
+	}
+	if !feed.Updated.IsZero() {
+		channel.LastBuildDate = feed.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, item := range feed.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        item.ID,
+			Description: item.Summary,
+			Categories:  item.Tags,
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return rssDoc{Version: "2.0", Channel: channel}
+}
+
+type atomDoc struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Author     atomAuthor     `xml:"author"`
+	Content    atomContent    `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func toAtom(feed *domain.Feed) atomDoc {
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	doc := atomDoc{
+		ID:      feed.ID,
+		Title:   feed.Title,
+		Link:    atomLink{Href: feed.Link},
+		Updated: updated.UTC().Format(time.RFC3339),
+	}
+
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Link:      atomLink{Href: item.Link},
+			Author:    atomAuthor{Name: item.Author},
+			Content:   atomContent{Type: "html", Body: item.Summary},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return doc
+}
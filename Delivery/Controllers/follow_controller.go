@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	usecases "github.com/gedyzed/blog-starter-project/Usecases"
+	"github.com/gin-gonic/gin"
+)
+
+type FollowController struct {
+	followUsecase *usecases.FollowUsecases
+}
+
+func NewFollowController(fu *usecases.FollowUsecases) *FollowController {
+	return &FollowController{followUsecase: fu}
+}
+
+func (fc *FollowController) Follow(c *gin.Context) {
+
+	ctx := c.Request.Context()
+	followerID := c.GetString("userID")
+	followeeID := c.Param("id")
+
+	if err := fc.followUsecase.Follow(ctx, followerID, followeeID); err != nil {
+		writeFollowError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "user followed successfully"})
+}
+
+func (fc *FollowController) Unfollow(c *gin.Context) {
+
+	ctx := c.Request.Context()
+	followerID := c.GetString("userID")
+	followeeID := c.Param("id")
+
+	if err := fc.followUsecase.Unfollow(ctx, followerID, followeeID); err != nil {
+		writeFollowError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "user unfollowed successfully"})
+}
+
+// HomeFeed lists the latest blogs from the authors the user follows
+func (fc *FollowController) HomeFeed(c *gin.Context) {
+
+	ctx := c.Request.Context()
+	userID := c.GetString("userID")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	feed, err := fc.followUsecase.HomeFeed(ctx, userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+func writeFollowError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrUserNotFound, domain.ErrInvalidUserID:
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case domain.ErrCannotFollowSelf:
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case domain.ErrAlreadyFollowing, domain.ErrNotFollowing:
+		c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+	c.Abort()
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/gin-gonic/gin"
+)
+
+type JobController struct {
+	jobUsecase domain.JobUsecase
+}
+
+func NewJobController(ju domain.JobUsecase) *JobController {
+	return &JobController{jobUsecase: ju}
+}
+
+// GetJobs lists background jobs, most recently updated first
+func (jc *JobController) GetJobs(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	jobs, total, err := jc.jobUsecase.GetJobs(c.Request.Context(), c.Query("status"), page, limit)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  jobs,
+		"total": total,
+	})
+}
+
+func (jc *JobController) RequeueJob(c *gin.Context) {
+	if err := jc.jobUsecase.RequeueJob(c.Request.Context(), c.Param("id")); err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Job requeued successfully"})
+}
+
+func (jc *JobController) RequeueDeadJobs(c *gin.Context) {
+	requeued, err := jc.jobUsecase.RequeueDeadJobs(c.Request.Context())
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Jobs requeued successfully",
+		"requeued": requeued,
+	})
+}
+
+// GetRefreshStats returns the popularity refresh counters since the server started
+func (jc *JobController) GetRefreshStats(c *gin.Context) {
+	c.JSON(http.StatusOK, jc.jobUsecase.GetRefreshStats())
+}
+
+func writeJobError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "job not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "No dead job with this ID"})
+	case "invalid status", "invalid pagination params":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
@@ -31,7 +31,7 @@ func (oa *OAuthController) OAuthCallBack(c *gin.Context) {
 	ctx := c.Request.Context()
 	code := c.Query("code")
 
-	tokens, err := oa.services.OAuthCallBack(ctx, code)
+	tokens, err := oa.services.OAuthCallBack(ctx, code, deviceFrom(c))
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
 		c.Abort()
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController struct {
+	reportUsecase domain.ReportUsecase
+}
+
+func NewReportController(ru domain.ReportUsecase) *ReportController {
+	return &ReportController{reportUsecase: ru}
+}
+
+func (rc *ReportController) ReportBlog(c *gin.Context) {
+	var input domain.ReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	report, err := rc.reportUsecase.ReportBlog(c.Request.Context(), c.GetString("userID"), c.Param("id"), input)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+func (rc *ReportController) ReportComment(c *gin.Context) {
+	var input domain.ReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	report, err := rc.reportUsecase.ReportComment(c.Request.Context(), c.GetString("userID"), c.Param("blogId"), c.Param("id"), input)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+func (rc *ReportController) ReportUser(c *gin.Context) {
+	var input domain.ReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	report, err := rc.reportUsecase.ReportUser(c.Request.Context(), c.GetString("userID"), c.Param("id"), input)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// GetReports lists reported targets with their report counts, most reported first
+func (rc *ReportController) GetReports(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	targetType := c.Query("target_type")
+	status := c.DefaultQuery("status", domain.ReportStatusOpen)
+
+	summaries, total, err := rc.reportUsecase.GetReportSummaries(c.Request.Context(), targetType, status, page, limit)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  summaries,
+		"total": total,
+	})
+}
+
+func (rc *ReportController) GetTargetReports(c *gin.Context) {
+	reports, err := rc.reportUsecase.GetTargetReports(c.Request.Context(), c.Param("targetType"), c.Param("targetId"))
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reports})
+}
+
+func (rc *ReportController) ResolveReports(c *gin.Context) {
+	var input domain.ReportResolutionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be one of dismiss, resolve, hide or remove"})
+		return
+	}
+
+	resolved, err := rc.reportUsecase.ResolveReports(c.Request.Context(), c.GetString("userID"), c.Param("targetType"), c.Param("targetId"), input)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Reports resolved successfully",
+		"resolved": resolved,
+	})
+}
+
+func writeReportError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "blog not found", "comment not found", "user not found", "no open reports":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "already reported":
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reported this"})
+	case "cannot report your own content":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "invalid report reason", "invalid target type", "action not supported for users":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	usecases "github.com/gedyzed/blog-starter-project/Usecases"
+	"github.com/gin-gonic/gin"
+)
+
+type SitemapController struct {
+	sitemapUsecase domain.SitemapUsecase
+	baseURL        string
+}
+
+func NewSitemapController(su domain.SitemapUsecase, baseURL string) *SitemapController {
+	return &SitemapController{
+		sitemapUsecase: su,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Sitemap serves the whole sitemap, or a sitemap index pointing at
+// /sitemaps/:page once there are more URLs than one sitemap may hold
+func (sc *SitemapController) Sitemap(c *gin.Context) {
+	urls, err := sc.sitemapUsecase.GetSitemapURLs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build sitemap"})
+		return
+	}
+
+	if len(urls) <= usecases.SitemapMaxURLs {
+		writeXML(c, toURLSet(urls))
+		return
+	}
+
+	index := sitemapIndex{}
+	for page := 1; (page-1)*usecases.SitemapMaxURLs < len(urls); page++ {
+		chunk := sitemapPage(urls, page)
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{
+			Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", sc.baseURL, page),
+			LastMod: formatLastMod(latestLastMod(chunk)),
+		})
+	}
+	writeXML(c, index)
+}
+
+// SitemapPage serves one numbered part of a split sitemap, e.g. /sitemaps/2.xml
+func (sc *SitemapController) SitemapPage(c *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(c.Param("page"), ".xml"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "sitemap not found"})
+		return
+	}
+
+	urls, err := sc.sitemapUsecase.GetSitemapURLs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build sitemap"})
+		return
+	}
+
+	chunk := sitemapPage(urls, page)
+	if len(chunk) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "sitemap not found"})
+		return
+	}
+	writeXML(c, toURLSet(chunk))
+}
+
+func (sc *SitemapController) Robots(c *gin.Context) {
+	robots := "User-agent: *\n" +
+		"Allow: /\n" +
+		"Disallow: /admin/\n" +
+		"Disallow: /admins/\n" +
+		"Disallow: /ai/\n" +
+		"Disallow: /oauth/\n" +
+		"Disallow: /tokens/\n" +
+		"\n" +
+		"Sitemap: " + sc.baseURL + "/sitemap.xml\n"
+
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(robots))
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func toURLSet(urls []domain.SitemapURL) urlSet {
+	set := urlSet{URLs: make([]sitemapURL, 0, len(urls))}
+	for _, u := range urls {
+		set.URLs = append(set.URLs, sitemapURL{Loc: u.Loc, LastMod: formatLastMod(u.LastMod)})
+	}
+	return set
+}
+
+func sitemapPage(urls []domain.SitemapURL, page int) []domain.SitemapURL {
+	start := (page - 1) * usecases.SitemapMaxURLs
+	if start >= len(urls) {
+		return nil
+	}
+	return urls[start:min(start+usecases.SitemapMaxURLs, len(urls))]
+}
+
+func latestLastMod(urls []domain.SitemapURL) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.LastMod.After(latest) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeXML(c *gin.Context, doc any) {
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render sitemap"})
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
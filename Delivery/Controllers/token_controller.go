@@ -66,4 +66,32 @@ func (ec *TokenController) SendPasswordRestCode (c *gin.Context) {
 	}
 
 	c.IndentedJSON(200, gin.H{"message": "we have send verifcation code to your email. Please check your email!"})
-}
\ No newline at end of file
+}
+
+// JWKS publishes the public keys that verify access tokens
+func (ec *TokenController) JWKS(c *gin.Context) {
+	jwks, err := ec.tokenUsecase.JWKS(c.Request.Context())
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": "Internal server error. Try again later"})
+		c.Abort()
+		return
+	}
+
+	c.IndentedJSON(200, jwks)
+}
+
+// RotateSigningKey starts signing access tokens with a new key; tokens signed before keep working until they expire
+func (ec *TokenController) RotateSigningKey(c *gin.Context) {
+	key, err := ec.tokenUsecase.RotateSigningKey(c.Request.Context())
+	if err != nil {
+		if err == domain.ErrSymmetricSigning {
+			c.IndentedJSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.IndentedJSON(500, gin.H{"error": "Internal server error. Try again later"})
+		}
+		c.Abort()
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "signing key rotated", "key": key})
+}
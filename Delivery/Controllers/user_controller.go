@@ -52,13 +52,21 @@ func (uc *UserController) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	token, err := uc.userUsecase.RefreshToken(c.Request.Context(), user.ID.Hex(), requestBody.RefreshToken)
+	token, err := uc.userUsecase.RefreshToken(c.Request.Context(), user.ID.Hex(), requestBody.RefreshToken, deviceFrom(c))
 	if err != nil {
 		switch err {
 		case usecases.ErrExpiredRefreshToken:
 			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "expired refresh token"})
 			c.Abort()
 			return
+		case domain.ErrRefreshTokenReused:
+			c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		case domain.ErrInvalidRefreshToken:
+			c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			c.Abort()
+			return
 		case domain.ErrTokenNotFound:
 			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "token not found"})
 			c.Abort()
@@ -87,7 +95,7 @@ func (uc *UserController) Logout(c *gin.Context) {
 		return
 	}
 
-	err := uc.userUsecase.Logout(c.Request.Context(), username)
+	err := uc.userUsecase.Logout(c.Request.Context(), username, c.GetString("userID"), c.GetString("sessionID"))
 	if err != nil {
 		switch err {
 		case domain.ErrUserNotFound:
@@ -138,7 +146,7 @@ func (uc *UserController) Login(c *gin.Context) {
 		Password: requestBody.Password,
 	}
 
-	token, err := uc.userUsecase.Login(ctx, user)
+	token, err := uc.userUsecase.Login(ctx, user, deviceFrom(c))
 	if err != nil {
 		switch err {
 		case usecases.ErrInvalidCredential:
@@ -162,6 +170,69 @@ func (uc *UserController) Login(c *gin.Context) {
 	})
 }
 
+// GetSessions lists the devices the user is signed in on
+func (uc *UserController) GetSessions(c *gin.Context) {
+	sessions, err := uc.userUsecase.GetSessions(c.Request.Context(), c.GetString("userID"), c.GetString("sessionID"))
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		c.Abort()
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// RevokeSession signs the user out of one of their devices
+func (uc *UserController) RevokeSession(c *gin.Context) {
+	err := uc.userUsecase.RevokeSession(c.Request.Context(), c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		switch err {
+		case domain.ErrTokenNotFound:
+			c.IndentedJSON(http.StatusNotFound, gin.H{"error": "session does not exist"})
+		default:
+			c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		c.Abort()
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// RevokeOtherSessions signs the user out of every device but the current one
+func (uc *UserController) RevokeOtherSessions(c *gin.Context) {
+	revoked, err := uc.userUsecase.RevokeOtherSessions(c.Request.Context(), c.GetString("userID"), c.GetString("sessionID"))
+	if err != nil {
+		switch err {
+		case domain.ErrTokenNotFound:
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "sign in again to manage your sessions"})
+		default:
+			c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		c.Abort()
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "other sessions revoked", "revoked": revoked})
+}
+
+// GetSecurityEvents lists suspicious activity on the user's account, such as reused refresh tokens
+func (uc *UserController) GetSecurityEvents(c *gin.Context) {
+	events, err := uc.userUsecase.GetSecurityEvents(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		c.Abort()
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"data": events})
+}
+
+// deviceFrom describes the device a sign in request came from
+func deviceFrom(c *gin.Context) domain.Device {
+	return domain.Device{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
 func (uc *UserController) RegisterUser(c *gin.Context) {
     ctx := c.Request.Context()
 
@@ -356,3 +427,23 @@ func (uc *UserController) ProfileUpdate(c *gin.Context) {
 
 	c.IndentedJSON(200, gin.H{"message": "Profile has been updated successfully"})
 }
+
+func (uc *UserController) GetPublicProfile(c *gin.Context) {
+
+	ctx := c.Request.Context()
+	userID := c.Param("id")
+
+	profile, err := uc.userUsecase.GetPublicProfile(ctx, userID)
+	if err != nil {
+		switch err {
+		case domain.ErrUserNotFound, domain.ErrInvalidUserID:
+			c.IndentedJSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		c.Abort()
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, profile)
+}
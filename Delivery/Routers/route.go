@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	controllers "github.com/gedyzed/blog-starter-project/Delivery/Controllers"
+	domain "github.com/gedyzed/blog-starter-project/Domain"
 	infrastructure "github.com/gedyzed/blog-starter-project/Infrastructure"
 )
 
@@ -12,27 +13,54 @@ func RegisterBlogRoutes(r *gin.Engine, blogHandler *controllers.BlogHandler, com
 	blog := r.Group("/blogs")
 	{
 		// Public routes
-		blog.GET("/", blogHandler.GetAllBlogs)
-		blog.GET("/:id", blogHandler.GetBlogById)
+		blog.GET("/", authMiddleware.OptionalLogin, blogHandler.GetAllBlogs)
+		blog.GET("/:id", authMiddleware.OptionalLogin, blogHandler.GetBlogById)
+		blog.GET("/by-slug/:slug", authMiddleware.OptionalLogin, blogHandler.GetBlogBySlug)
 		blog.GET("/filter", blogHandler.FilterBlogs)
 		blog.GET("/search", blogHandler.SearchBlogs)
 
 		// Protected routes
+		blog.GET("/mine", authMiddleware.IsLogin, blogHandler.GetMyBlogs)
 		blog.POST("/", authMiddleware.IsLogin, blogHandler.CreateBlog)
 		blog.PUT("/:id", authMiddleware.IsLogin, blogHandler.UpdateBlog)
+		blog.PATCH("/:id/status", authMiddleware.IsLoginWithRole(), blogHandler.UpdateBlogStatus)
+		blog.GET("/:id/revisions", authMiddleware.IsLogin, blogHandler.GetRevisions)
+		blog.GET("/:id/revisions/diff", authMiddleware.IsLogin, blogHandler.GetRevisionDiff)
+		blog.POST("/:id/revisions/:revisionId/restore", authMiddleware.IsLogin, blogHandler.RestoreRevision)
 		blog.DELETE("/:id", authMiddleware.IsLoginWithRole(), blogHandler.DeleteBlog)
 		blog.POST("/:id/like", authMiddleware.IsLogin, blogHandler.LikeBlog)
 		blog.POST("/:id/dislike", authMiddleware.IsLogin, blogHandler.DislikeBlog)
+		blog.GET("/:id/reactions", blogHandler.GetReactions)
+		blog.POST("/:id/reactions", authMiddleware.IsLogin, blogHandler.ReactToBlog)
+		blog.DELETE("/:id/reactions", authMiddleware.IsLogin, blogHandler.RemoveReaction)
 	}
 
 	comments := r.Group("/comments")
 	{
 		comments.POST("/:blogId", authMiddleware.IsLogin, commentHandler.CreateComment)
-		comments.GET("/:blogId", commentHandler.GetAllComments)
-		comments.GET("/:blogId/:id", commentHandler.GetCommentByID)
+		comments.GET("/:blogId", authMiddleware.OptionalLogin, commentHandler.GetAllComments)
+		comments.GET("/:blogId/:id", authMiddleware.OptionalLogin, commentHandler.GetCommentByID)
+		comments.POST("/:blogId/:id/replies", authMiddleware.IsLogin, commentHandler.CreateReply)
+		comments.GET("/:blogId/:id/replies", authMiddleware.OptionalLogin, commentHandler.GetReplies)
 		comments.PUT("/:blogId/:id", authMiddleware.IsLogin, commentHandler.EditComment)
 		comments.DELETE("/:blogId/:id", authMiddleware.IsLoginWithRole(), commentHandler.DeleteComment)
 	}
+
+	// post authors moderate comments on their own blogs, admins on every blog
+	moderation := r.Group("/admin/moderation")
+	moderation.Use(authMiddleware.IsLoginWithRole())
+	{
+		moderation.GET("", commentHandler.GetModerationQueue)
+		moderation.POST("", commentHandler.ModerateComments)
+	}
+
+	adminBlogs := r.Group("/admin/blogs")
+	adminBlogs.Use(authMiddleware.IsLoginWithRole())
+	adminBlogs.Use(authMiddleware.RequireAdmin())
+	adminBlogs.Use(authMiddleware.RequireScope(domain.ScopeAdmin))
+	{
+		adminBlogs.POST("/recompute-scores", blogHandler.RecomputeScores)
+	}
 }
 
 func RegisterUserRoutes(r *gin.Engine, handler *controllers.UserController, authMiddleware *infrastructure.AuthMiddleware) {
@@ -42,21 +70,27 @@ func RegisterUserRoutes(r *gin.Engine, handler *controllers.UserController, auth
 	{
 		users.POST("/register", handler.RegisterUser)
 		users.POST("/login", handler.Login)
-		users.DELETE("/logout/:username", handler.Logout)
 		users.POST("/forgot-password", handler.ForgotPassword)
 		users.POST("/reset-password", handler.ResetPassword)
 		users.POST("/token/refresh_token", handler.RefreshToken)
+		users.GET("/:id/profile", handler.GetPublicProfile)
 	}
 
 	protectedUser := r.Group("/users")
 	protectedUser.Use(authMiddleware.IsLogin)
 	{
 		protectedUser.POST("/update-profile", handler.ProfileUpdate)
+		protectedUser.DELETE("/logout/:username", handler.Logout)
+		protectedUser.GET("/me/sessions", handler.GetSessions)
+		protectedUser.DELETE("/me/sessions", handler.RevokeOtherSessions)
+		protectedUser.DELETE("/me/sessions/:id", handler.RevokeSession)
+		protectedUser.GET("/me/security-events", handler.GetSecurityEvents)
 	}
 
 	protectedAdmins := r.Group("/admins")
 	protectedAdmins.Use(authMiddleware.IsLoginWithRole())
 	protectedAdmins.Use(authMiddleware.RequireAdmin())
+	protectedAdmins.Use(authMiddleware.RequireScope(domain.ScopeAdmin))
 	{
 		protectedAdmins.POST("/promote-demote", handler.PromoteDemoteUser)
 	}
@@ -72,6 +106,19 @@ func RegisterTokenRoutes(r *gin.Engine, handler *controllers.TokenController) {
 	}
 }
 
+func RegisterKeyRoutes(r *gin.Engine, handler *controllers.TokenController, authMiddleware *infrastructure.AuthMiddleware) {
+
+	r.GET("/.well-known/jwks.json", handler.JWKS)
+
+	adminKeys := r.Group("/admin/keys")
+	adminKeys.Use(authMiddleware.IsLoginWithRole())
+	adminKeys.Use(authMiddleware.RequireAdmin())
+	adminKeys.Use(authMiddleware.RequireScope(domain.ScopeAdmin))
+	{
+		adminKeys.POST("/rotate", handler.RotateSigningKey)
+	}
+}
+
 func RegisterOAuthRoutes(r *gin.Engine, handler *controllers.OAuthController) {
 
 	oauth := r.Group("/oauth")
@@ -91,3 +138,92 @@ func RegisterGenerativeAIRoutes(r *gin.Engine, handler *controllers.GenerativeAI
 		protectedAI.GET("/generate", handler.GenerativeAI)
 	}
 }
+
+func RegisterFeedRoutes(r *gin.Engine, handler *controllers.FeedController) {
+
+	feeds := r.Group("/feeds")
+
+	{
+		feeds.GET("/rss.xml", handler.RSS)
+		feeds.GET("/atom.xml", handler.Atom)
+		feeds.GET("/authors/:userId", handler.AuthorFeed)
+		feeds.GET("/tags/:tag", handler.TagFeed)
+	}
+}
+
+func RegisterFollowRoutes(r *gin.Engine, handler *controllers.FollowController, authMiddleware *infrastructure.AuthMiddleware) {
+
+	r.POST("/users/:id/follow", authMiddleware.IsLogin, handler.Follow)
+	r.DELETE("/users/:id/follow", authMiddleware.IsLogin, handler.Unfollow)
+	r.GET("/feed", authMiddleware.IsLogin, handler.HomeFeed)
+}
+
+func RegisterReportRoutes(r *gin.Engine, handler *controllers.ReportController, authMiddleware *infrastructure.AuthMiddleware) {
+
+	r.POST("/blogs/:id/report", authMiddleware.IsLogin, handler.ReportBlog)
+	r.POST("/comments/:blogId/:id/report", authMiddleware.IsLogin, handler.ReportComment)
+	r.POST("/users/:id/report", authMiddleware.IsLogin, handler.ReportUser)
+
+	reports := r.Group("/admin/reports")
+	reports.Use(authMiddleware.IsLoginWithRole())
+	reports.Use(authMiddleware.RequireAdmin())
+	reports.Use(authMiddleware.RequireScope(domain.ScopeAdmin))
+	{
+		reports.GET("", handler.GetReports)
+		reports.GET("/:targetType/:targetId", handler.GetTargetReports)
+		reports.POST("/:targetType/:targetId/resolve", handler.ResolveReports)
+	}
+}
+
+func RegisterAnalyticsRoutes(r *gin.Engine, handler *controllers.AnalyticsController, authMiddleware *infrastructure.AuthMiddleware) {
+
+	r.GET("/blogs/mine/analytics", authMiddleware.IsLogin, handler.GetDashboard)
+	r.GET("/blogs/:id/analytics", authMiddleware.IsLoginWithRole(), handler.GetBlogAnalytics)
+}
+
+func RegisterJobRoutes(r *gin.Engine, handler *controllers.JobController, authMiddleware *infrastructure.AuthMiddleware) {
+
+	jobs := r.Group("/admin/jobs")
+	jobs.Use(authMiddleware.IsLoginWithRole())
+	jobs.Use(authMiddleware.RequireAdmin())
+	jobs.Use(authMiddleware.RequireScope(domain.ScopeAdmin))
+	{
+		jobs.GET("", handler.GetJobs)
+		jobs.GET("/refresh-stats", handler.GetRefreshStats)
+		jobs.POST("/requeue", handler.RequeueDeadJobs)
+		jobs.POST("/:id/requeue", handler.RequeueJob)
+	}
+}
+
+func RegisterBookmarkRoutes(r *gin.Engine, handler *controllers.BookmarkHandler, authMiddleware *infrastructure.AuthMiddleware) {
+
+	bookmarks := r.Group("/bookmarks")
+	bookmarks.Use(authMiddleware.IsLogin)
+	{
+		bookmarks.POST("", handler.AddBookmark)
+		bookmarks.GET("", handler.GetBookmarks)
+		bookmarks.PATCH("/:id", handler.UpdateBookmark)
+		bookmarks.DELETE("/:id", handler.RemoveBookmark)
+	}
+
+	lists := r.Group("/reading-lists")
+	lists.Use(authMiddleware.IsLogin)
+	{
+		lists.POST("", handler.CreateReadingList)
+		lists.GET("", handler.GetReadingLists)
+		lists.PUT("/:id", handler.UpdateReadingList)
+		lists.DELETE("/:id", handler.DeleteReadingList)
+		lists.PUT("/:id/order", handler.ReorderReadingList)
+		lists.POST("/:id/share", handler.ShareReadingList)
+		lists.DELETE("/:id/share", handler.UnshareReadingList)
+	}
+
+	r.GET("/shared/reading-lists/:token", handler.GetSharedReadingList)
+}
+
+func RegisterSitemapRoutes(r *gin.Engine, handler *controllers.SitemapController) {
+
+	r.GET("/sitemap.xml", handler.Sitemap)
+	r.GET("/sitemaps/:page", handler.SitemapPage)
+	r.GET("/robots.txt", handler.Robots)
+}
@@ -42,38 +42,158 @@ func main() {
 	userCollection := db.Collection("users")
 	tokenCollection := db.Collection("tokens")
 	vtokenCollection := db.Collection("vtokens")
+	revisionCollection := db.Collection("blog_revisions")
+	reportCollection := db.Collection("reports")
+	followCollection := db.Collection("follows")
+	bookmarkCollection := db.Collection("bookmarks")
+	readingListCollection := db.Collection("reading_lists")
+	reactionCollection := db.Collection("reactions")
+	voteCollection := db.Collection("votes")
+	viewCollection := db.Collection("blog_views")
+	analyticsCollection := db.Collection("blog_stats")
+	jobCollection := db.Collection("jobs")
+	securityEventCollection := db.Collection("security_events")
+	revokedTokenCollection := db.Collection("revoked_tokens")
+	signingKeyCollection := db.Collection("signing_keys")
 
 	// Setup repo
 	tokenRepo := repository.NewMongoTokenRepository(tokenCollection)
 	vtokenRepo := repository.NewMongoVTokenRepository(vtokenCollection)
 	userRepo := repository.NewMongoUserRepo(userCollection)
+	followRepo := repository.NewMongoFollowRepo(followCollection)
+	securityEventRepo := repository.NewMongoSecurityEventRepo(securityEventCollection)
+	revokedTokenRepo := repository.NewMongoRevokedTokenRepo(revokedTokenCollection)
+	signingKeyRepo := repository.NewMongoSigningKeyRepo(signingKeyCollection)
 
-	blogRepo := repository.NewBlogRepository(blogCollection, userRepo, lruCache.BlogCache(), lruCache.SortedBlogsCache())
+	bookmarkRepo := repository.NewBookmarkRepository(bookmarkCollection, readingListCollection)
+	blogRepo := repository.NewBlogRepository(blogCollection, userRepo, lruCache.BlogCache(), lruCache.SlugCache(), lruCache.SortedBlogsCache(), lruCache.FeedCache(), lruCache.SitemapCache(), bookmarkRepo)
 	commentRepo := repository.NewCommentRepository(commentCollection, blogCollection, userRepo, lruCache.CommentCache())
+	revisionRepo := repository.NewRevisionRepository(revisionCollection)
+	reportRepo := repository.NewReportRepository(reportCollection)
+	reactionRepo := repository.NewReactionRepository(reactionCollection, userRepo)
+	voteRepo := repository.NewVoteRepository(voteCollection, blogCollection)
+	viewRepo := repository.NewViewRepository(viewCollection)
+	analyticsRepo := repository.NewAnalyticsRepository(analyticsCollection)
+	jobRepo := repository.NewJobRepository(jobCollection)
 
 	//to initialize the indexes
 	if err := blogRepo.EnsureIndexes(context.Background()); err != nil {
 		log.Fatalf("Failed to create indexes: %v", err)
 	}
+	if err := revisionRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create revision indexes: %v", err)
+	}
+	if err := commentRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create comment indexes: %v", err)
+	}
+	if err := reportRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create report indexes: %v", err)
+	}
+	if err := followRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create follow indexes: %v", err)
+	}
+	if err := bookmarkRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create bookmark indexes: %v", err)
+	}
+	if err := reactionRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create reaction indexes: %v", err)
+	}
+	if err := voteRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create vote indexes: %v", err)
+	}
+	if err := viewRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create view indexes: %v", err)
+	}
+	if err := analyticsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create analytics indexes: %v", err)
+	}
+	if err := jobRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create job indexes: %v", err)
+	}
+	if err := tokenRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create session indexes: %v", err)
+	}
+	if err := securityEventRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create security event indexes: %v", err)
+	}
+	if err := revokedTokenRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create revoked token indexes: %v", err)
+	}
+	if err := signingKeyRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create signing key indexes: %v", err)
+	}
+	// blogs stored before votes had their own collection kept them in liked_users/disliked_users
+	if migrated, err := voteRepo.MigrateBlogVotes(context.Background()); err != nil {
+		log.Fatalf("Failed to migrate blog votes: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated votes of %d blogs", migrated)
+	}
 
-	dispatcher := infrastructure.NewBlogQueue()
+	jobQueue := infrastructure.NewJobQueue(jobRepo, infrastructure.JobQueuePolicy{
+		Workers:     conf.Queue.Workers,
+		MaxAttempts: conf.Queue.MaxAttempts,
+		RetryBase:   conf.Queue.RetryBase,
+		RetryMax:    conf.Queue.RetryMax,
+		Lease:       conf.Queue.Lease,
+	})
+	dispatcher := infrastructure.NewRefreshDispatcher(jobQueue, conf.Queue.RefreshWindow, conf.Queue.RefreshBatch)
 	// Setup services
 	passService := infrastructure.NewPasswordService()
+	markdownService := infrastructure.NewMarkdownService()
 	vtokenService := infrastructure.NewTokenService(conf.Email, conf.App.URL)
+	keyRing, err := infrastructure.NewKeyRing(signingKeyRepo, conf.Auth.SigningAlg, conf.Auth.AccessTTL, conf.Auth.SigningKeySecret)
+	if err != nil {
+		log.Fatalf("Failed to set up signing keys: %v", err)
+	}
+	if err := keyRing.Load(context.Background()); err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
 	tokenService := infrastructure.NewJWTTokenService(
 		tokenRepo,
-		conf.Auth.AccessTokenKey,
-		conf.Auth.RefreshTokenKey,
-		30*(24*time.Hour), // 1 month
-		60*(24*time.Hour), // 2 month
+		userRepo,
+		revokedTokenRepo,
+		securityEventRepo,
+		keyRing,
+		infrastructure.TokenPolicy{
+			AccessKey:  conf.Auth.AccessTokenKey,
+			RefreshKey: conf.Auth.RefreshTokenKey,
+			AccessTTL:  conf.Auth.AccessTTL,
+			RefreshTTL: conf.Auth.RefreshTTL,
+			Issuer:     conf.Auth.Issuer,
+			Audience:   conf.Auth.Audience,
+		},
 	)
 
 	// Setup usecases
 	tokenUsecase := usecases.NewTokenUsecase(tokenRepo, vtokenRepo, vtokenService, tokenService)
-	userUsecase := usecases.NewUserUsecase(userRepo, tokenUsecase, passService)
+	userUsecase := usecases.NewUserUsecase(userRepo, followRepo, securityEventRepo, tokenUsecase, passService)
+	followUsecase := usecases.NewFollowUsecase(followRepo, userRepo, blogRepo)
+
+	reactionPolicy := usecases.ReactionPolicy{
+		Types:   conf.Reaction.Types,
+		Weights: conf.Reaction.Weights,
+	}
+	scoring, err := usecases.NewScoringStrategy(conf.Scoring.Strategy, usecases.ScoringWeights(conf.Scoring.Weights), reactionPolicy, conf.Scoring.Gravity)
+	if err != nil {
+		log.Fatalf("Failed to set up scoring: %v", err)
+	}
 
-	blogUsecase := usecases.NewBlogUsecase(blogRepo, commentRepo, dispatcher)
-	commentUsecase := usecases.NewCommentUsecase(commentRepo, dispatcher)
+	blogUsecase := usecases.NewBlogUsecase(blogRepo, commentRepo, revisionRepo, reactionRepo, voteRepo, viewRepo, analyticsRepo, dispatcher, markdownService, reactionPolicy, scoring, conf.View.DedupWindow, usecases.TrendingPolicy{
+		HalfLife: conf.Trending.HalfLife,
+		Window:   conf.Trending.Window,
+		Weights:  usecases.TrendingWeights(conf.Trending.Weights),
+	})
+	commentUsecase := usecases.NewCommentUsecase(commentRepo, blogRepo, userRepo, analyticsRepo, dispatcher, markdownService, usecases.CommentPolicy{
+		MaxDepth:     conf.Comment.MaxDepth,
+		Moderation:   conf.Comment.Moderation,
+		TrustedAfter: conf.Comment.TrustedAfter,
+	})
+	reportUsecase := usecases.NewReportUsecase(reportRepo, blogRepo, commentRepo, userRepo, blogUsecase, commentUsecase, dispatcher, conf.Report.HideThreshold)
+	feedUsecase := usecases.NewFeedUsecase(blogRepo, userRepo, lruCache.FeedCache(), conf.App.URL)
+	sitemapUsecase := usecases.NewSitemapUsecase(blogRepo, lruCache.SitemapCache(), conf.App.URL)
+	bookmarkUsecase := usecases.NewBookmarkUsecase(bookmarkRepo, blogRepo, conf.App.URL)
+	analyticsUsecase := usecases.NewAnalyticsUsecase(analyticsRepo, blogRepo)
+	jobUsecase := usecases.NewJobUsecase(jobRepo, dispatcher)
 
 	// oauth servcive
 	oauthService := oauth.NewOAuthServices(googleOauthConfig, userUsecase)
@@ -85,19 +205,37 @@ func main() {
 	tokenHandler := controllers.NewTokenController(tokenUsecase)
 	oAuthHandler := controllers.NewOAuthController(googleOauthConfig, oauthService)
 	genAIHandler := controllers.NewGenerativeAIController(&conf.AI)
+	feedHandler := controllers.NewFeedController(feedUsecase)
+	sitemapHandler := controllers.NewSitemapController(sitemapUsecase, conf.App.URL)
+	reportHandler := controllers.NewReportController(reportUsecase)
+	followHandler := controllers.NewFollowController(followUsecase)
+	bookmarkHandler := controllers.NewBookmarkHandler(bookmarkUsecase)
+	analyticsHandler := controllers.NewAnalyticsController(analyticsUsecase)
+	jobHandler := controllers.NewJobController(jobUsecase)
 
 	// middlewares
 	authMiddleware := infrastructure.NewAuthMiddleware(tokenService, oauthService, userUsecase)
 
-	infrastructure.StartBlogRefreshWorker(ctx, blogUsecase)
+	dispatcher.HandleWith(blogUsecase.RefreshPopularity)
+	dispatcher.Start(ctx)
+	jobQueue.Start(ctx)
+	infrastructure.StartBlogPublishScheduler(ctx, blogUsecase, time.Minute)
 
 	r := gin.Default()
 
 	routers.RegisterUserRoutes(r, userHandler, authMiddleware)
 	routers.RegisterTokenRoutes(r, tokenHandler)
+	routers.RegisterKeyRoutes(r, tokenHandler, authMiddleware)
 	routers.RegisterOAuthRoutes(r, oAuthHandler)
 	routers.RegisterGenerativeAIRoutes(r, genAIHandler, authMiddleware)
 	routers.RegisterBlogRoutes(r, blogHandler, commentHandler, authMiddleware)
+	routers.RegisterFeedRoutes(r, feedHandler)
+	routers.RegisterSitemapRoutes(r, sitemapHandler)
+	routers.RegisterReportRoutes(r, reportHandler, authMiddleware)
+	routers.RegisterFollowRoutes(r, followHandler, authMiddleware)
+	routers.RegisterBookmarkRoutes(r, bookmarkHandler, authMiddleware)
+	routers.RegisterAnalyticsRoutes(r, analyticsHandler, authMiddleware)
+	routers.RegisterJobRoutes(r, jobHandler, authMiddleware)
 
 	r.Run(":" + conf.Port)
 }
@@ -38,63 +38,572 @@ type Profile struct {
 	UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
+// Blog lifecycle statuses
+const (
+	BlogStatusDraft     = "draft"
+	BlogStatusInReview  = "in_review"
+	BlogStatusScheduled = "scheduled"
+	BlogStatusPublished = "published"
+	BlogStatusArchived  = "archived"
+)
+
 // Blog represents a blog post
 type Blog struct {
-	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"` // uses MongoDB's native ObjectID
-	UserID          primitive.ObjectID `json:"user_id" bson:"user_id"`
-	AuthorName      string             `json:"author_name" bson:"author_name"`
-	Title           string             `json:"title" bson:"title"`
-	Content         string             `json:"content" bson:"content"`
-	Created         time.Time          `json:"created" bson:"created"`
-	Updated         time.Time          `json:"updated" bson:"updated"`
-	ViewCount       int                `json:"view_count" bson:"view_count"`
-	Tags            []string           `json:"tags" bson:"tags"`
-	Likes           int                `json:"likes" bson:"likes"`
-	Dislikes        int                `json:"dislikes" bson:"dislikes"`
-	LikedUsers      []string           `json:"liked_users" bson:"liked_users"`
-	DislikedUsers   []string           `json:"disliked_users" bson:"disliked_users"`
-	CommentsCount   int                `json:"comments_count" bson:"comments_count"`
-	PopularityScore float64            `json:"popularity_score" bson:"popularity_score"`
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"` // uses MongoDB's native ObjectID
+	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
+	AuthorName       string             `json:"author_name" bson:"author_name"`
+	Title            string             `json:"title" bson:"title"`
+	Slug             string             `json:"slug" bson:"slug,omitempty"`
+	PreviousSlugs    []string           `json:"-" bson:"previous_slugs,omitempty"`          // kept so old links redirect to the current slug
+	Content          string             `json:"content,omitempty" bson:"content"`           // Markdown source
+	ContentHTML      string             `json:"content_html,omitempty" bson:"content_html"` // sanitized HTML rendered from Content
+	Created          time.Time          `json:"created" bson:"created"`
+	Updated          time.Time          `json:"updated" bson:"updated"`
+	ViewCount        int                `json:"view_count" bson:"view_count"`               // every view by a person, refreshes included
+	UniqueViewCount  int                `json:"unique_view_count" bson:"unique_view_count"` // views deduplicated per visitor within the view window
+	Tags             []string           `json:"tags" bson:"tags"`
+	Likes            int                `json:"likes" bson:"likes"`
+	Dislikes         int                `json:"dislikes" bson:"dislikes"`
+	Reactions        map[string]int     `json:"reactions" bson:"reactions,omitempty"` // count of each reaction type
+	MyVote           string             `json:"my_vote,omitempty" bson:"-"`           // "like" or "dislike" when the requesting user voted
+	MyReactions      []string           `json:"my_reactions,omitempty" bson:"-"`      // reactions the requesting user left
+	CommentsCount    int                `json:"comments_count" bson:"comments_count"`
+	PopularityScore  float64            `json:"popularity_score" bson:"popularity_score"`
+	TrendingScore    float64            `json:"trending_score" bson:"trending_score"` // ranks recent activity for sort=trending
+	Status           string             `json:"status" bson:"status"`
+	ModerateComments bool               `json:"moderate_comments" bson:"moderate_comments"`       // hold comments from untrusted users for review
+	Hidden           bool               `json:"hidden,omitempty" bson:"hidden,omitempty"`         // hidden after abuse reports
+	PublishAt        *time.Time         `json:"publish_at,omitempty" bson:"publish_at,omitempty"` // go-live time of a scheduled blog
+	PublishedAt      *time.Time         `json:"published_at,omitempty" bson:"published_at,omitempty"`
 }
 
-// Comment represents a comment on a blog post
-type Comment struct {
+// IsPublished reports whether the blog is publicly visible.
+// Blogs stored before the status field existed have no status and are treated as published.
+func (b *Blog) IsPublished() bool {
+	return !b.Hidden && (b.Status == "" || b.Status == BlogStatusPublished)
+}
+
+// UniqueViews returns the deduplicated view count.
+// Blogs viewed only before unique views were counted fall back to their raw view count.
+func (b *Blog) UniqueViews() int {
+	if b.UniqueViewCount == 0 {
+		return b.ViewCount
+	}
+	return b.UniqueViewCount
+}
+
+// Viewer identifies who requested a blog; UserID is empty for anonymous visitors
+type Viewer struct {
+	UserID    string
+	IP        string
+	UserAgent string
+}
+
+// BlogRevision is an immutable snapshot of a blog taken right before it was edited
+type BlogRevision struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	BlogID   primitive.ObjectID `json:"blog_id" bson:"blog_id"`
+	Version  int                `json:"version" bson:"version"`
+	Title    string             `json:"title" bson:"title"`
+	Content  string             `json:"content" bson:"content"`
+	Tags     []string           `json:"tags" bson:"tags"`
+	EditedBy primitive.ObjectID `json:"edited_by" bson:"edited_by"` // user whose edit replaced this version
+	Created  time.Time          `json:"created" bson:"created"`
+}
+
+// DiffLine is one line of a line-level diff; Op is "+" for added, "-" for removed and " " for unchanged
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type BlogRevisionDiff struct {
+	From    string     `json:"from"`
+	To      string     `json:"to"`
+	Title   []DiffLine `json:"title"`
+	Content []DiffLine `json:"content"`
+}
+
+type PaginatedRevisionResponse struct {
+	Revisions   []BlogRevision `json:"revisions"`
+	TotalCount  int            `json:"total_count"`
+	TotalPages  int            `json:"total_pages"`
+	CurrentPage int            `json:"current_page"`
+}
+
+// Bookmark is a blog a user saved for later, optionally filed in one of their reading lists
+type Bookmark struct {
+	ID       primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID   primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	BlogID   primitive.ObjectID  `json:"blog_id" bson:"blog_id"`
+	ListID   *primitive.ObjectID `json:"list_id,omitempty" bson:"list_id,omitempty"`
+	Position int                 `json:"position" bson:"position"` // order inside the reading list
+	Read     bool                `json:"read" bson:"read"`
+	ReadAt   *time.Time          `json:"read_at,omitempty" bson:"read_at,omitempty"`
+	Created  time.Time           `json:"created" bson:"created"`
+	Blog     *Blog               `json:"blog,omitempty" bson:"-"` // filled in when bookmarks are listed
+}
+
+// ReadingList is a named collection of bookmarks that can be shared through a public link
+type ReadingList struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	ShareToken  string             `json:"-" bson:"share_token,omitempty"`
+	ShareURL    string             `json:"share_url,omitempty" bson:"-"` // set for shared lists when returned to their owner
+	Created     time.Time          `json:"created" bson:"created"`
+	Updated     time.Time          `json:"updated" bson:"updated"`
+}
+
+// BookmarkInput saves a blog, optionally straight into a reading list
+type BookmarkInput struct {
+	BlogID string `json:"blog_id" binding:"required"`
+	ListID string `json:"list_id"`
+}
+
+// BookmarkUpdateInput moves a bookmark to another reading list or marks it read;
+// omitted fields are left unchanged and an empty list_id removes it from its list
+type BookmarkUpdateInput struct {
+	ListID *string `json:"list_id"`
+	Read   *bool   `json:"read"`
+}
+
+// ReadingListInput creates or renames a reading list
+type ReadingListInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// ReorderInput lists every bookmark of a reading list in its new order
+type ReorderInput struct {
+	BookmarkIDs []string `json:"bookmark_ids" binding:"required"`
+}
+
+type PaginatedBookmarkResponse struct {
+	Bookmarks   []Bookmark `json:"bookmarks"`
+	TotalCount  int        `json:"total_count"`
+	TotalPages  int        `json:"total_pages"`
+	CurrentPage int        `json:"current_page"`
+}
+
+// Reaction is one user's emoji reaction to a blog; a user can leave several reactions of different types
+type Reaction struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	BlogID   primitive.ObjectID `json:"blog_id" bson:"blog_id"`
+	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
+	UserName string             `json:"user_name" bson:"user_name"`
+	Type     string             `json:"type" bson:"type"`
+	Created  time.Time          `json:"created" bson:"created"`
+}
+
+type ReactionInput struct {
+	Type string `json:"type" binding:"required"`
+}
+
+type PaginatedReactionResponse struct {
+	Reactions   []Reaction `json:"reactions"`
+	TotalCount  int        `json:"total_count"`
+	TotalPages  int        `json:"total_pages"`
+	CurrentPage int        `json:"current_page"`
+}
+
+// BlogStats are the computed statistics of a blog saved by a popularity refresh
+type BlogStats struct {
+	BlogID          string
+	PopularityScore float64
+	TrendingScore   float64
+	CommentCount    int
+}
+
+// ScoreInput is the lifetime activity of a blog that its popularity score is computed from
+type ScoreInput struct {
+	UniqueViews int
+	Likes       int
+	Dislikes    int
+	Comments    int
+	Reactions   map[string]int
+	Published   time.Time
+}
+
+// Analytics granularities
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+// StatCounters are a blog's activity counts over a period; likes, dislikes and reactions are net changes
+type StatCounters struct {
+	Views       int `json:"views" bson:"views"`
+	UniqueViews int `json:"unique_views" bson:"unique_views"`
+	Likes       int `json:"likes" bson:"likes"`
+	Dislikes    int `json:"dislikes" bson:"dislikes"`
+	Reactions   int `json:"reactions" bson:"reactions"`
+	Comments    int `json:"comments" bson:"comments"`
+}
+
+// Add sums two sets of counters
+func (s StatCounters) Add(o StatCounters) StatCounters {
+	return StatCounters{
+		Views:       s.Views + o.Views,
+		UniqueViews: s.UniqueViews + o.UniqueViews,
+		Likes:       s.Likes + o.Likes,
+		Dislikes:    s.Dislikes + o.Dislikes,
+		Reactions:   s.Reactions + o.Reactions,
+		Comments:    s.Comments + o.Comments,
+	}
+}
+
+// StatBucket holds the counters of one hour or day, starting at Bucket
+type StatBucket struct {
+	Bucket       time.Time `json:"bucket" bson:"bucket"`
+	StatCounters `bson:",inline"`
+}
+
+// BlogStatSummary is the activity of one blog over a period
+type BlogStatSummary struct {
+	BlogID       primitive.ObjectID `json:"blog_id" bson:"_id"`
+	Title        string             `json:"title" bson:"-"`
+	StatCounters `bson:",inline"`
+}
+
+type BlogAnalytics struct {
+	BlogID      primitive.ObjectID `json:"blog_id"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Granularity string             `json:"granularity"`
+	Totals      StatCounters       `json:"totals"`
+	Series      []StatBucket       `json:"series"`
+}
+
+// AuthorDashboard aggregates the activity of all of an author's blogs
+type AuthorDashboard struct {
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Granularity string            `json:"granularity"`
+	Totals      StatCounters      `json:"totals"`
+	Series      []StatBucket      `json:"series"`
+	TopBlogs    []BlogStatSummary `json:"top_blogs"` // most viewed blogs of the period
+}
+
+// AnalyticsQuery selects the period and bucket size of analytics; zero times fall back to defaults
+type AnalyticsQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+}
+
+// FeedCacheGroup is the sort key all cached feeds are stored under, so they are invalidated together
+const FeedCacheGroup = "feeds"
+
+// Feed is a syndication feed of published blogs, rendered as RSS or Atom by the delivery layer
+type Feed struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Link        string     `json:"link"`
+	Updated     time.Time  `json:"updated"`
+	Items       []FeedItem `json:"items"`
+	Modified    time.Time  `json:"-"` // when the feed was built; unlike Updated it never moves back when a blog leaves the feed
+}
+
+type FeedItem struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Link      string    `json:"link"`
+	Author    string    `json:"author"`
+	Summary   string    `json:"summary"` // sanitized HTML
+	Tags      []string  `json:"tags"`
+	Published time.Time `json:"published"`
+	Updated   time.Time `json:"updated"`
+}
+
+// SitemapCacheGroup is the sort key the generated sitemap is cached under
+const SitemapCacheGroup = "sitemap"
+
+// SitemapURL is one <url> entry of the sitemap
+type SitemapURL struct {
+	Loc     string    `json:"loc"`
+	LastMod time.Time `json:"lastmod"`
+}
+
+// LastUpdated pairs a key (an author ID or a tag) with the latest update of its published blogs
+type LastUpdated struct {
+	Key     string    `json:"key" bson:"key"`
+	Updated time.Time `json:"updated" bson:"updated"`
+}
+
+// PublicProfile is the part of a user shown to everyone on their author page
+type PublicProfile struct {
+	ID             string    `json:"id"`
+	Username       string    `json:"username"`
+	Firstname      string    `json:"firstname"`
+	Lastname       string    `json:"lastname"`
+	Bio            string    `json:"bio"`
+	ProfilePic     string    `json:"profile_picture"`
+	Location       string    `json:"location"`
+	JoinedAt       time.Time `json:"joined_at"`
+	FollowersCount int       `json:"followers_count"`
+	FollowingCount int       `json:"following_count"`
+}
+
+// Follow records that a user subscribed to an author
+type Follow struct {
 	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	BlogID     primitive.ObjectID `json:"blog_id" bson:"blog_id"`
-	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
-	AuthorName string             `json:"author_name" bson:"author_name"`
-	Message    string             `json:"message" bson:"message"`
+	FollowerID primitive.ObjectID `json:"follower_id" bson:"follower_id"`
+	FolloweeID primitive.ObjectID `json:"followee_id" bson:"followee_id"`
 	Created    time.Time          `json:"created" bson:"created"`
-	Updated    time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
-// Token represents authentication tokens
+// Comment represents a comment on a blog post
+type Comment struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	BlogID       primitive.ObjectID  `json:"blog_id" bson:"blog_id"`
+	UserID       primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	AuthorName   string              `json:"author_name" bson:"author_name"`
+	Message      string              `json:"message" bson:"message"`            // Markdown source
+	MessageHTML  string              `json:"message_html" bson:"message_html"`  // sanitized HTML rendered from Message
+	BlogAuthorID primitive.ObjectID  `json:"-" bson:"blog_author_id,omitempty"` // lets post authors moderate comments on their blogs
+	Status       string              `json:"status" bson:"status"`
+	ParentID     *primitive.ObjectID `json:"parent_id,omitempty" bson:"parent_id,omitempty"` // nil for top-level comments
+	Depth        int                 `json:"depth" bson:"depth"`                             // 0 for top-level comments
+	ReplyCount   int                 `json:"reply_count" bson:"reply_count"`
+	Deleted      bool                `json:"deleted" bson:"deleted"`                   // tombstone kept because the comment has replies
+	Hidden       bool                `json:"hidden,omitempty" bson:"hidden,omitempty"` // hidden after abuse reports
+	Created      time.Time           `json:"created" bson:"created"`
+	Updated      time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+// DeletedCommentMessage replaces the message of a deleted comment that still has replies
+const DeletedCommentMessage = "[deleted]"
+
+// Comment moderation statuses
+const (
+	CommentStatusApproved = "approved"
+	CommentStatusPending  = "pending"
+	CommentStatusRejected = "rejected"
+)
+
+// IsApproved reports whether the comment is publicly visible.
+// Comments stored before moderation existed have no status and are treated as approved.
+func (c *Comment) IsApproved() bool {
+	return c.Status == "" || c.Status == CommentStatusApproved
+}
+
+// ModerationInput approves or rejects pending comments in bulk
+type ModerationInput struct {
+	CommentIDs []string `json:"comment_ids" binding:"required,min=1"`
+	Action     string   `json:"action" binding:"required,oneof=approve reject"`
+}
+
+// Report target types
+const (
+	ReportTargetBlog    = "blog"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+// Report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// Report is a reader's abuse report against a blog, comment or user
+type Report struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TargetType string              `json:"target_type" bson:"target_type"`
+	TargetID   primitive.ObjectID  `json:"target_id" bson:"target_id"`
+	BlogID     *primitive.ObjectID `json:"blog_id,omitempty" bson:"blog_id,omitempty"` // blog of a reported comment
+	ReporterID primitive.ObjectID  `json:"reporter_id" bson:"reporter_id"`
+	Reason     string              `json:"reason" bson:"reason"`
+	Details    string              `json:"details,omitempty" bson:"details,omitempty"`
+	Status     string              `json:"status" bson:"status"`
+	ResolvedBy *primitive.ObjectID `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	ResolvedAt *time.Time          `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	Created    time.Time           `json:"created" bson:"created"`
+}
+
+// ReportInput is the body of a new report
+type ReportInput struct {
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details" binding:"max=1000"`
+}
+
+// ReportSummary aggregates the reports filed against one target
+type ReportSummary struct {
+	TargetType    string              `json:"target_type" bson:"target_type"`
+	TargetID      primitive.ObjectID  `json:"target_id" bson:"target_id"`
+	BlogID        *primitive.ObjectID `json:"blog_id,omitempty" bson:"blog_id,omitempty"`
+	Count         int                 `json:"count" bson:"count"`
+	Reasons       []string            `json:"reasons" bson:"reasons"`
+	FirstReported time.Time           `json:"first_reported" bson:"first_reported"`
+	LastReported  time.Time           `json:"last_reported" bson:"last_reported"`
+}
+
+// ReportResolutionInput is an admin's decision on the open reports against a target
+type ReportResolutionInput struct {
+	Action string `json:"action" binding:"required,oneof=dismiss resolve hide remove"`
+}
+
+// Token represents the authentication tokens of one session; a user has a session per signed in device
 type Token struct {
+	ID            primitive.ObjectID `json:"session_id" bson:"_id,omitempty"`
 	UserID        string 			 `json:"user_id" bson:"user_id"`
 	AccessToken   string             `json:"access_token" bson:"access_token"`
 	RefreshToken  string             `json:"refresh_token" bson:"refresh_token"`
 	AccessExpiry  time.Time          `json:"access_expiry" bson:"access_expiry"`
 	RefreshExpiry time.Time          `json:"refresh_expiry" bson:"refresh_expiry"`
+	AccessTokenID string             `json:"-" bson:"access_token_id"` // jti of the access token; only the latest one of a session is valid
+	Generation    int                `json:"-" bson:"generation"` // how many times the refresh token was rotated
+	UserAgent     string             `json:"-" bson:"user_agent"`
+	IP            string             `json:"-" bson:"ip"`
+	LastUsedAt    time.Time          `json:"-" bson:"last_used_at"`
 	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt     time.Time          `json:"expires_at" bson:"expires_at"`
 }
 
-// Like represents a like on a blog post
-type Like struct {
-	ID        string    `json:"id" bson:"like_id"`
-	BlogID    string    `json:"blog_id" bson:"blog_id"`
-	UserID    string    `json:"user_id" bson:"user_id"`
-	Liked     bool      `json:"liked" bson:"liked"`
-	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+// Device is where a session was signed in from
+type Device struct {
+	UserAgent string
+	IP        string
 }
 
-// Dislike represents a dislike on a blog post
-type Dislike struct {
-	ID        string    `json:"id" bson:"dislike_id"`
-	BlogID    string    `json:"blog_id" bson:"blog_id"`
-	UserID    string    `json:"user_id" bson:"user_id"`
-	Disliked  bool      `json:"disliked" bson:"disliked"`
-	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+// Session is a signed in device as listed to its owner; last used is when it last signed in or refreshed its tokens
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+// Security event types
+const (
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse" // a rotated refresh token was used again, so its session was revoked
+)
+
+// SecurityEvent records something suspicious that happened to a user's account
+type SecurityEvent struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"-" bson:"user_id"`
+	Type      string             `json:"type" bson:"type"`
+	SessionID string             `json:"session_id,omitempty" bson:"session_id,omitempty"`
+	UserAgent string             `json:"user_agent" bson:"user_agent"`
+	IP        string             `json:"ip" bson:"ip"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// RevokedToken denies an access token before it expires; it is removed once the token would have expired anyway
+type RevokedToken struct {
+	TokenID   string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// SigningKey is a key pair access tokens are signed with; the kid header of a token names its key.
+// Only the newest active key signs, retired keys verify the tokens they signed until ExpiresAt.
+type SigningKey struct {
+	ID         string     `json:"kid" bson:"_id"`
+	Algorithm  string     `json:"alg" bson:"alg"`
+	PrivateKey string     `json:"-" bson:"private_key"` // PKCS #8, PEM encoded, encrypted with AES-GCM and base64 encoded
+	PublicKey  string     `json:"-" bson:"public_key"`  // PKIX, PEM encoded
+	Active     bool       `json:"active" bson:"active"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Ed25519 curve
+	X   string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// JWKS is the set of keys that verify our access tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Scopes of access tokens
+const (
+	ScopeRead  = "read"  // read content, including your own drafts and analytics
+	ScopeWrite = "write" // create and change blogs, comments, reactions, bookmarks and follows
+	ScopeAdmin = "admin" // the admin endpoints
+)
+
+// ScopesForRole returns the scopes access tokens of a user with the role are granted
+func ScopesForRole(role string) []string {
+	if role == "admin" {
+		return []string{ScopeRead, ScopeWrite, ScopeAdmin}
+	}
+	return []string{ScopeRead, ScopeWrite}
+}
+
+// AccessClaims is what a verified access token tells about its bearer; Role and Scopes are empty
+// for Google sessions, whose tokens carry no claims
+type AccessClaims struct {
+	UserID    string
+	SessionID string
+	TokenID   string
+	Role      string
+	Scopes    []string
+}
+
+// Vote types
+const (
+	VoteLike    = "like"
+	VoteDislike = "dislike"
+)
+
+// Vote is a user's like or dislike of a blog post; a user has at most one vote per blog
+type Vote struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	BlogID    primitive.ObjectID `json:"blog_id" bson:"blog_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Type      string             `json:"type" bson:"type"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Job types
+const (
+	JobRefreshPopularity = "refresh_popularity" // payload is a comma separated list of blog IDs
+)
+
+// Job statuses
+const (
+	JobStatusPending = "pending" // waiting for run_at
+	JobStatusRunning = "running" // claimed by a worker until locked_until
+	JobStatusDead    = "dead"    // gave up after the last attempt; kept until an admin requeues it
+)
+
+// Job is a unit of background work kept in MongoDB until it succeeds, so it survives restarts.
+// A running job whose worker died is picked up again once its lock expires.
+type Job struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Type        string             `json:"type" bson:"type"`
+	Payload     string             `json:"payload" bson:"payload"`
+	Status      string             `json:"status" bson:"status"`
+	Attempts    int                `json:"attempts" bson:"attempts"`
+	RunAt       time.Time          `json:"run_at" bson:"run_at"`
+	LockedUntil *time.Time         `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	LeaseID     primitive.ObjectID `json:"-" bson:"lease_id,omitempty"` // set by every claim; only the worker holding it may finish the job
+	LastError   string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	Created     time.Time          `json:"created" bson:"created"`
+	Updated     time.Time          `json:"updated" bson:"updated"`
+}
+
+// DispatcherStats counts popularity refresh requests since the server started
+type DispatcherStats struct {
+	Enqueued  int64 `json:"enqueued"`  // refreshes asked for
+	Coalesced int64 `json:"coalesced"` // refreshes merged into one already waiting for the same blog
+	Processed int64 `json:"processed"` // blogs refreshed by the workers
 }
 
 // AISuggestion represents AI-generated suggestions
@@ -115,10 +624,18 @@ type EmailRequest struct {
 
 // BlogUpdateInput for updating a blog
 type BlogUpdateInput struct {
-	UserID  string   `json:"user_id"`
-	Title   string   `json:"title"`
-	Content string   `json:"content"`
-	Tags    []string `json:"tags"`
+	UserID           string   `json:"user_id"`
+	Title            string   `json:"title"`
+	Content          string   `json:"content"`
+	ContentHTML      string   `json:"-"` // rendered by the usecase, never taken from the request
+	Tags             []string `json:"tags"`
+	ModerateComments *bool    `json:"moderate_comments"` // left unchanged when omitted
+}
+
+// BlogStatusInput for moving a blog to another lifecycle status
+type BlogStatusInput struct {
+	Status    string     `json:"status" binding:"required"`
+	PublishAt *time.Time `json:"publish_at"` // required when status is scheduled
 }
 
 type PaginatedBlogResponse struct {
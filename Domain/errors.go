@@ -24,9 +24,13 @@ var (
 	// Token errors
 	ErrInvalidToken        		= errors.New("invalid access token")
 	ErrInvalidRefreshToken 		= errors.New("invalid refresh token")
+	ErrExpiredRefreshToken      = errors.New("refresh token has expired")
+	ErrRefreshTokenReused       = errors.New("refresh token was already used, the session has been revoked")
 	ErrMissingOrInvalidHeader 	= errors.New("missing or invalid authorization header")
 	ErrTokenDoesNotMatch		= errors.New("token does not match the stored token")
 	ErrTokenNotFound            = errors.New("token not found")
+	ErrTokenRevoked             = errors.New("access token has been revoked")
+	ErrSymmetricSigning         = errors.New("signing keys are only used with RS256 or EdDSA")
 
 	// OAuth errors 
 	ErrFailedToDecodeUserInfo = errors.New("failed to decode user information")
@@ -35,6 +39,14 @@ var (
 	ErrFailedToExchange    	  = errors.New("failed to exchange authorization code")
 	ErrFailedToFetchUserInfo  = errors.New("failed to fetch user information from Google")
 
+	// Follow errors
+	ErrAlreadyFollowing = errors.New("already following this user")
+	ErrNotFollowing     = errors.New("not following this user")
+	ErrCannotFollowSelf = errors.New("you cannot follow yourself")
+
+	// Revision errors
+	ErrRevisionConflict = errors.New("the blog was edited concurrently, try again")
+
 	//Email Errors
 	ErrFailedToSendEmail 		= errors.New("failed to send email")
 	ErrLoginWithUsernameAndPassword = errors.New("login with your username and password")
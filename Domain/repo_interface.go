@@ -7,28 +7,131 @@ import (
 )
 
 type BlogRepository interface {
-	GetAllBlogs(ctx context.Context, page int, limit int, sort string) ([]Blog, int, error)
+	GetAllBlogs(ctx context.Context, page int, limit int, sort string, status string) ([]Blog, int, error)
+	GetBlogsByAuthor(ctx context.Context, userID string, status string, page int, limit int) ([]Blog, int, error)
+	GetBlogsByAuthors(ctx context.Context, authorIDs []string, page int, limit int) ([]Blog, int, error)
+	GetBlogsByIDs(ctx context.Context, ids []string) ([]Blog, error)
 	GetBlogByID(ctx context.Context, id string) (*Blog, error)
-	IncrementBlogViews(ctx context.Context, id string) error
+	GetBlogsAfter(ctx context.Context, afterID string, limit int) ([]Blog, error)
+	GetBlogBySlug(ctx context.Context, slug string) (*Blog, error)
+	IncrementBlogViews(ctx context.Context, id string, unique bool) error
 	CreateBlog(ctx context.Context, blog Blog, userID string) (*Blog, error)
-	UpdateBlog(ctx context.Context, id string, userID string, updatedBlog BlogUpdateInput) error
+	// UpdateBlog only applies the edit while the blog was last updated at lastUpdated and returns ErrRevisionConflict otherwise
+	UpdateBlog(ctx context.Context, id string, userID string, updatedBlog BlogUpdateInput, lastUpdated time.Time) error
+	UpdateBlogStatus(ctx context.Context, id string, status string, publishAt *time.Time) error
+	PublishDueBlogs(ctx context.Context, now time.Time) ([]string, error)
 	DeleteBlog(ctx context.Context, id string) error
-	LikeBlog(ctx context.Context, blogID string, userID string) error
-	DislikeBlog(ctx context.Context, blogID string, userID string) error
+	SetBlogHidden(ctx context.Context, id string, hidden bool) error
+	IncrementVotes(ctx context.Context, blogID string, likes int, dislikes int) error
+	IncrementReaction(ctx context.Context, blogID string, reactionType string, delta int) error
 	EnsureIndexes(ctx context.Context) error
-	UpdateStats(ctx context.Context, blogID string, score float64, commentCount int) error
-	FilterBlogs(ctx context.Context, startDate, endDate *time.Time, tags []string, sort string, page, limit int) ([]Blog, int, error)
+	UpdateStats(ctx context.Context, stats []BlogStats) error
+	UpdatePopularityScores(ctx context.Context, scores map[string]float64) error
+	FilterBlogs(ctx context.Context, authorID string, startDate, endDate *time.Time, tags []string, sort string, page, limit int) ([]Blog, int, error)
 	SearchBlogs(ctx context.Context, keyword string, limit, page int) ([]Blog, int, error)
+	GetSitemapBlogs(ctx context.Context) ([]Blog, error)
+	GetAuthorsLastUpdated(ctx context.Context) ([]LastUpdated, error)
+	GetTagsLastUpdated(ctx context.Context) ([]LastUpdated, error)
+}
+
+type BlogRevisionRepository interface {
+	CreateRevision(ctx context.Context, revision BlogRevision) (*BlogRevision, error)
+	GetRevisions(ctx context.Context, blogID string, page int, limit int) ([]BlogRevision, int, error)
+	GetRevisionByID(ctx context.Context, blogID string, id string) (*BlogRevision, error)
+	DeleteRevision(ctx context.Context, blogID string, id string) error
+	DeleteRevisionsByBlogID(ctx context.Context, blogID string) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type BookmarkRepository interface {
+	CreateBookmark(ctx context.Context, bookmark Bookmark) (*Bookmark, error)
+	GetBookmarks(ctx context.Context, userID string, listID string, unreadOnly bool, page int, limit int) ([]Bookmark, int, error)
+	GetBookmarkByID(ctx context.Context, userID string, id string) (*Bookmark, error)
+	GetListBookmarkIDs(ctx context.Context, userID string, listID string) ([]string, error)
+	NextPosition(ctx context.Context, userID string, listID string) (int, error)
+	MoveBookmark(ctx context.Context, userID string, id string, listID string, position int) error
+	SetBookmarkRead(ctx context.Context, userID string, id string, read bool) error
+	SetBookmarkPositions(ctx context.Context, userID string, ids []string) error
+	DeleteBookmark(ctx context.Context, userID string, id string) error
+	DeleteBookmarksByBlogID(ctx context.Context, blogID string) error
+	CreateReadingList(ctx context.Context, list ReadingList) (*ReadingList, error)
+	GetReadingLists(ctx context.Context, userID string) ([]ReadingList, error)
+	GetReadingListByID(ctx context.Context, userID string, id string) (*ReadingList, error)
+	GetReadingListByShareToken(ctx context.Context, token string) (*ReadingList, error)
+	UpdateReadingList(ctx context.Context, userID string, id string, input ReadingListInput) error
+	SetShareToken(ctx context.Context, userID string, id string, token string) error
+	DeleteReadingList(ctx context.Context, userID string, id string) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type ReactionRepository interface {
+	AddReaction(ctx context.Context, blogID string, userID string, reactionType string) (*Reaction, error)
+	RemoveReaction(ctx context.Context, blogID string, userID string, reactionType string) error
+	GetReactions(ctx context.Context, blogID string, reactionType string, page int, limit int) ([]Reaction, int, error)
+	GetUserReactions(ctx context.Context, userID string, blogIDs []string) (map[string][]string, error)
+	DeleteReactionsByBlogID(ctx context.Context, blogID string) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type AnalyticsRepository interface {
+	Record(ctx context.Context, blogID string, authorID string, at time.Time, delta StatCounters) error
+	GetBlogSeries(ctx context.Context, blogID string, from time.Time, to time.Time) ([]StatBucket, error)
+	GetSeriesByBlogIDs(ctx context.Context, blogIDs []string, from time.Time, to time.Time) (map[string][]StatBucket, error)
+	GetAuthorSeries(ctx context.Context, authorID string, from time.Time, to time.Time) ([]StatBucket, error)
+	GetAuthorTopBlogs(ctx context.Context, authorID string, from time.Time, to time.Time, limit int) ([]BlogStatSummary, error)
+	DeleteByBlogID(ctx context.Context, blogID string) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type ViewRepository interface {
+	RecordView(ctx context.Context, blogID string, visitor string, window time.Duration) (unique bool, err error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type VoteRepository interface {
+	ToggleVote(ctx context.Context, blogID string, userID string, voteType string) (likes int, dislikes int, err error)
+	GetUserVotes(ctx context.Context, userID string, blogIDs []string) (map[string]string, error)
+	DeleteVotesByBlogID(ctx context.Context, blogID string) error
+	MigrateBlogVotes(ctx context.Context) (int, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type JobRepository interface {
+	CreateJob(ctx context.Context, job Job) error
+	ClaimJob(ctx context.Context, now time.Time, lease time.Duration) (*Job, error)
+	CompleteJob(ctx context.Context, id string, leaseID string) error
+	RetryJob(ctx context.Context, id string, leaseID string, runAt time.Time, lastError string) error
+	BuryJob(ctx context.Context, id string, leaseID string, lastError string) error
+	GetJobs(ctx context.Context, status string, page int, limit int) ([]Job, int, error)
+	RequeueJob(ctx context.Context, id string) error
+	RequeueDeadJobs(ctx context.Context) (int, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type ReportRepository interface {
+	CreateReport(ctx context.Context, report Report) (*Report, error)
+	CountOpenReports(ctx context.Context, targetType string, targetID string) (int, error)
+	GetReportSummaries(ctx context.Context, targetType string, status string, page int, limit int) ([]ReportSummary, int, error)
+	GetReportsByTarget(ctx context.Context, targetType string, targetID string) ([]Report, error)
+	ResolveReports(ctx context.Context, targetType string, targetID string, status string, resolvedBy string) (int, error)
+	EnsureIndexes(ctx context.Context) error
 }
 
 type CommentRepository interface {
 	CreateComment(ctx context.Context, blogID string, userID string, comment Comment) (*Comment, error)
-	GetAllComments(ctx context.Context, blogID string, page int, limit int, sort string) ([]*Comment, int, error)
+	GetAllComments(ctx context.Context, blogID string, viewerID string, page int, limit int, sort string) ([]*Comment, int, error)
+	GetReplies(ctx context.Context, blogID string, parentID string, viewerID string, page int, limit int, sort string) ([]*Comment, int, error)
 	GetCommentByID(ctx context.Context, blogID string, id string) (*Comment, error)
-	EditComment(ctx context.Context, blogID string, id string, userID string, message string) error
+	EditComment(ctx context.Context, blogID string, id string, userID string, message string, messageHTML string) error
 	DeleteComment(ctx context.Context, blogID string, id string, userID string) error
 	DeleteCommentByID(ctx context.Context, blogID string, commentID string) error
 	CountCommentsByBlogID(ctx context.Context, id string) (int, error)
+	CountCommentsByBlogIDs(ctx context.Context, ids []string) (map[string]int, error)
+	CountApprovedCommentsByUser(ctx context.Context, userID string) (int, error)
+	GetPendingComments(ctx context.Context, blogAuthorID string, page int, limit int) ([]*Comment, int, error)
+	SetCommentsStatus(ctx context.Context, ids []string, blogAuthorID string, status string) ([]*Comment, error)
+	SetCommentHidden(ctx context.Context, blogID string, id string, hidden bool) error
+	EnsureIndexes(ctx context.Context) error
 }
 
 type Cache[T any] interface{
@@ -47,6 +150,9 @@ type BlogCache interface{
 	BlogCache() Cache[*Blog]
     CommentCache() SortedCache[[]*Comment]
 	SortedBlogsCache() SortedCache[[]Blog]
+	FeedCache() SortedCache[*Feed]
+	SitemapCache() SortedCache[[]SitemapURL]
+	SlugCache() Cache[string]
 }
 
 type IUserRepository interface {
@@ -59,11 +165,43 @@ type IUserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*User, error)
 }
 
+type IFollowRepository interface {
+	Follow(ctx context.Context, followerID, followeeID string) error
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	CountFollowers(ctx context.Context, userID string) (int, error)
+	CountFollowing(ctx context.Context, userID string) (int, error)
+	GetFollowingIDs(ctx context.Context, userID string) ([]string, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
 type ITokenRepo interface{
 	Save(ctx context.Context, tokens *Token) error
-	FindByUserID(ctx context.Context, userID string) (*Token, error)
+	FindByID(ctx context.Context, sessionID string) (*Token, error)
+	FindByUserID(ctx context.Context, userID string) ([]Token, error)
+	FindByRefreshToken(ctx context.Context, refreshToken string) (*Token, error)
+	DeleteByID(ctx context.Context, userID, sessionID string) error
 	DeleteByUserID(ctx context.Context, userID string) error
-	FindByAccessToken (ctx context.Context, accessToken string) (string, error)
+	Rotate(ctx context.Context, tokens *Token, previousRefreshToken string) error
+	FindByAccessToken (ctx context.Context, accessToken string) (*Token, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type IRevokedTokenRepo interface {
+	Revoke(ctx context.Context, token *RevokedToken) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type ISigningKeyRepo interface {
+	GetKeys(ctx context.Context) ([]SigningKey, error)
+	Rotate(ctx context.Context, key *SigningKey, retireAt time.Time) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type ISecurityEventRepo interface {
+	Create(ctx context.Context, event *SecurityEvent) error
+	FindByUserID(ctx context.Context, userID string, limit int) ([]SecurityEvent, error)
+	EnsureIndexes(ctx context.Context) error
 }
 
 type IVTokenRepo interface {
@@ -78,21 +216,33 @@ type IPasswordService interface {
 	Verify(password, hashedPassword string) error
 }
 
+type IMarkdownService interface {
+	RenderBlog(source string) (string, error)
+	RenderComment(source string) (string, error)
+}
+
 type IVTokenService interface {
 	SendEmail(to []string, subject string, body string) error
 }
 
 type ITokenService interface {
-	GenerateTokens(ctx context.Context, userID string) (*Token, error)
-	VerifyAccessToken(string) (string, error)
-	RefreshTokens(ctx context.Context, refreshToken string) (*Token, error)
+	GenerateTokens(ctx context.Context, userID string, device Device) (*Token, error)
+	VerifyAccessToken(ctx context.Context, token string) (string, error)
+	ParseAccessToken(ctx context.Context, token string) (*AccessClaims, error)
+	RefreshTokens(ctx context.Context, userID, refreshToken string, device Device) (*Token, error)
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeSessions(ctx context.Context, userID, keepSessionID string) (int, error)
+	RevokeAccessTokens(ctx context.Context, userID string) error
+	JWKS(ctx context.Context) (*JWKS, error)
+	RotateSigningKey(ctx context.Context) (*SigningKey, error)
 }
 
 type IOAuthServices interface {
-	VerifyGoogleIDToken(ctx context.Context, token string) (string, error)
+	VerifyGoogleIDToken(ctx context.Context, token string) (*Token, error)
 	RefreshToken(ctx context.Context, token *Token)(*Token, error)
 	ResolveUserID(ctx context.Context, email string)(string, error)
-	OAuthCallBack(ctx context.Context, code string) (*Token, error)
+	OAuthCallBack(ctx context.Context, code string, device Device) (*Token, error)
 	
 }
 
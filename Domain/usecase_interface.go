@@ -6,28 +6,99 @@ import (
 )
 
 type BlogUsecase interface {
-	GetAllBlogs(ctx context.Context, page int, limit int, sort string) (*PaginatedBlogResponse, error)
-	ViewBlog(ctx context.Context, id string) (*Blog, error)
+	GetAllBlogs(ctx context.Context, page int, limit int, sort string, viewerID string) (*PaginatedBlogResponse, error)
+	GetMyBlogs(ctx context.Context, userID string, status string, page int, limit int) (*PaginatedBlogResponse, error)
+	ViewBlog(ctx context.Context, id string, viewer Viewer) (*Blog, error)
+	ResolveSlug(ctx context.Context, slug string) (id string, currentSlug string, err error)
 	CreateBlog(ctx context.Context, blog Blog, userID string) (*Blog, error)
 	UpdateBlog(ctx context.Context, id string, userID string, updatedBlog BlogUpdateInput) error
+	UpdateBlogStatus(ctx context.Context, id string, userID string, input BlogStatusInput) error
+	UpdateBlogStatusAsAdmin(ctx context.Context, id string, input BlogStatusInput) error
+	PublishDueBlogs(ctx context.Context) (int, error)
+	GetRevisions(ctx context.Context, blogID string, userID string, page int, limit int) (*PaginatedRevisionResponse, error)
+	GetRevisionDiff(ctx context.Context, blogID string, userID string, from string, to string) (*BlogRevisionDiff, error)
+	RestoreRevision(ctx context.Context, blogID string, userID string, revisionID string) error
 	DeleteBlog(ctx context.Context, id string, userID string) error
 	DeleteBlogAsAdmin(ctx context.Context, blogID string) error
 	LikeBlog(ctx context.Context, blogID string, userID string) error
 	DislikeBlog(ctx context.Context, blogID string, userID string) error
-	RefreshPopularity(ctx context.Context, blogID string) error
+	ReactToBlog(ctx context.Context, blogID string, userID string, reactionType string) (*Reaction, error)
+	RemoveReaction(ctx context.Context, blogID string, userID string, reactionType string) error
+	GetReactions(ctx context.Context, blogID string, reactionType string, page int, limit int) (*PaginatedReactionResponse, error)
+	RefreshPopularity(ctx context.Context, blogIDs []string) error
+	StartScoreRecompute() error
 	FilterBlogs(ctx context.Context, tags []string, startDate, endDate *time.Time, sortBy string, page int, limit int) (*PaginatedBlogResponse, error)
 	SearchBlogs(ctx context.Context, keyword string, page, limit int) (*PaginatedBlogResponse, error)
 }
 
 type CommentUsecase interface {
 	CreateComment(ctx context.Context, blogID string, userID string, message string) (*Comment, error)
-	GetAllComments(ctx context.Context, blogID string, page int, limit int, sort string) ([]*Comment, int, error)
-	GetCommentByID(ctx context.Context, blogID string, commentID string) (*Comment, error)
+	GetAllComments(ctx context.Context, blogID string, viewerID string, page int, limit int, sort string) ([]*Comment, int, error)
+	CreateReply(ctx context.Context, blogID string, parentID string, userID string, message string) (*Comment, error)
+	GetReplies(ctx context.Context, blogID string, parentID string, viewerID string, page int, limit int, sort string) ([]*Comment, int, error)
+	GetCommentByID(ctx context.Context, blogID string, commentID string, viewerID string) (*Comment, error)
 	EditComment(ctx context.Context, blogID string, commentID string, userID string, message string) error
 	DeleteComment(ctx context.Context, blogID string, commentID string, userID string) error
 	DeleteCommentAsAdmin(ctx context.Context, blogID string, commentID string) error
+	GetModerationQueue(ctx context.Context, userID string, page int, limit int) ([]*Comment, int, error)
+	GetModerationQueueAsAdmin(ctx context.Context, page int, limit int) ([]*Comment, int, error)
+	ModerateComments(ctx context.Context, userID string, input ModerationInput) (int, error)
+	ModerateCommentsAsAdmin(ctx context.Context, input ModerationInput) (int, error)
+}
+
+type BookmarkUsecase interface {
+	AddBookmark(ctx context.Context, userID string, input BookmarkInput) (*Bookmark, error)
+	GetBookmarks(ctx context.Context, userID string, listID string, unreadOnly bool, page int, limit int) (*PaginatedBookmarkResponse, error)
+	UpdateBookmark(ctx context.Context, userID string, id string, input BookmarkUpdateInput) error
+	RemoveBookmark(ctx context.Context, userID string, id string) error
+	CreateReadingList(ctx context.Context, userID string, input ReadingListInput) (*ReadingList, error)
+	GetReadingLists(ctx context.Context, userID string) ([]ReadingList, error)
+	UpdateReadingList(ctx context.Context, userID string, id string, input ReadingListInput) error
+	DeleteReadingList(ctx context.Context, userID string, id string) error
+	ReorderReadingList(ctx context.Context, userID string, id string, input ReorderInput) error
+	ShareReadingList(ctx context.Context, userID string, id string) (*ReadingList, error)
+	UnshareReadingList(ctx context.Context, userID string, id string) error
+	GetSharedReadingList(ctx context.Context, token string) (*ReadingList, []Bookmark, error)
+}
+
+type AnalyticsUsecase interface {
+	GetBlogAnalytics(ctx context.Context, blogID string, userID string, query AnalyticsQuery) (*BlogAnalytics, error)
+	GetBlogAnalyticsAsAdmin(ctx context.Context, blogID string, query AnalyticsQuery) (*BlogAnalytics, error)
+	GetAuthorDashboard(ctx context.Context, authorID string, query AnalyticsQuery) (*AuthorDashboard, error)
+}
+
+type ReportUsecase interface {
+	ReportBlog(ctx context.Context, reporterID string, blogID string, input ReportInput) (*Report, error)
+	ReportComment(ctx context.Context, reporterID string, blogID string, commentID string, input ReportInput) (*Report, error)
+	ReportUser(ctx context.Context, reporterID string, userID string, input ReportInput) (*Report, error)
+	GetReportSummaries(ctx context.Context, targetType string, status string, page int, limit int) ([]ReportSummary, int, error)
+	GetTargetReports(ctx context.Context, targetType string, targetID string) ([]Report, error)
+	ResolveReports(ctx context.Context, adminID string, targetType string, targetID string, input ReportResolutionInput) (int, error)
+}
+
+type JobUsecase interface {
+	GetJobs(ctx context.Context, status string, page int, limit int) ([]Job, int, error)
+	RequeueJob(ctx context.Context, id string) error
+	RequeueDeadJobs(ctx context.Context) (int, error)
+	GetRefreshStats() DispatcherStats
+}
+
+type FeedUsecase interface {
+	SiteFeed(ctx context.Context) (*Feed, error)
+	AuthorFeed(ctx context.Context, userID string) (*Feed, error)
+	TagFeed(ctx context.Context, tag string) (*Feed, error)
+}
+
+type SitemapUsecase interface {
+	GetSitemapURLs(ctx context.Context) ([]SitemapURL, error)
 }
 
 type BlogRefreshDispatcher interface {
 	Enqueue(blogID string)
+	Stats() DispatcherStats
+}
+
+// ScoringStrategy computes the popularity score that sort=popular orders blogs by
+type ScoringStrategy interface {
+	Score(input ScoreInput, now time.Time) float64
 }
@@ -87,6 +87,9 @@ type LRUCache struct{
 	blogCache 		 *genericCache[*domain.Blog]
     commentCache     *genericCache[[]*domain.Comment]
 	sortedCache 	 *genericCache[[]domain.Blog]
+	feedCache        *genericCache[*domain.Feed]
+	sitemapCache     *genericCache[[]domain.SitemapURL]
+	slugCache        *genericCache[string]
 }
 
 func NewLRUCache(size int) (*LRUCache, error){
@@ -105,10 +108,28 @@ func NewLRUCache(size int) (*LRUCache, error){
 		return nil,err
 	}
 
+	feedCache, err := NewGenericCache[*domain.Feed](size)
+	if err != nil{
+		return nil,err
+	}
+
+	sitemapCache, err := NewGenericCache[[]domain.SitemapURL](size)
+	if err != nil{
+		return nil,err
+	}
+
+	slugCache, err := NewGenericCache[string](size)
+	if err != nil{
+		return nil,err
+	}
+
 	return &LRUCache{
 		blogCache: blogCache,
 		commentCache: commentCache,
 		sortedCache: sortedCache,
+		feedCache: feedCache,
+		sitemapCache: sitemapCache,
+		slugCache: slugCache,
 	}, nil
 
 }
@@ -124,3 +145,15 @@ func (c *LRUCache) CommentCache() domain.SortedCache[[]*domain.Comment]{
 func (c *LRUCache) SortedBlogsCache() domain.SortedCache[[]domain.Blog] {
     return c.sortedCache
 }
+
+func (c *LRUCache) FeedCache() domain.SortedCache[*domain.Feed] {
+    return c.feedCache
+}
+
+func (c *LRUCache) SitemapCache() domain.SortedCache[[]domain.SitemapURL] {
+    return c.sitemapCache
+}
+
+func (c *LRUCache) SlugCache() domain.Cache[string] {
+    return c.slugCache
+}
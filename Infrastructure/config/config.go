@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"os"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
@@ -12,13 +13,20 @@ import (
 )
 
 type Config struct {
-	App   AppConfig   `mapstructure:"app" validate:"required"`
-	Port  string      `mapstructure:"port" validate:"required,min=1,max=65535"`
-	Mongo MongoConfig `mapstructure:"mongo" validate:"required"`
-	Auth  AuthConfig  `mapstructure:"auth" validate:"required"`
-	OAuth OAuthConfig `mapstructure:"oauth" validate:"required"`
-	Email EmailConfig `mapstructure:"email" validate:"required"`
-	AI    AIConfig    `mapstructure:"ai" validate:"required"`
+	App      AppConfig      `mapstructure:"app" validate:"required"`
+	Port     string         `mapstructure:"port" validate:"required,min=1,max=65535"`
+	Mongo    MongoConfig    `mapstructure:"mongo" validate:"required"`
+	Auth     AuthConfig     `mapstructure:"auth" validate:"required"`
+	OAuth    OAuthConfig    `mapstructure:"oauth" validate:"required"`
+	Email    EmailConfig    `mapstructure:"email" validate:"required"`
+	AI       AIConfig       `mapstructure:"ai" validate:"required"`
+	Comment  CommentConfig  `mapstructure:"comment"`
+	Report   ReportConfig   `mapstructure:"report"`
+	Reaction ReactionConfig `mapstructure:"reaction"`
+	View     ViewConfig     `mapstructure:"view"`
+	Trending TrendingConfig `mapstructure:"trending"`
+	Scoring  ScoringConfig  `mapstructure:"scoring"`
+	Queue    QueueConfig    `mapstructure:"queue"`
 }
 
 type MongoConfig struct {
@@ -29,6 +37,67 @@ type AppConfig struct {
 	URL string `mapstructure:"url" validate:"required,url"`
 }
 
+type CommentConfig struct {
+	MaxDepth     int    `mapstructure:"max_depth" validate:"min=1"`
+	Moderation   string `mapstructure:"moderation" validate:"oneof=off untrusted all"`
+	TrustedAfter int    `mapstructure:"trusted_after" validate:"min=0"`
+}
+
+type ReportConfig struct {
+	HideThreshold int `mapstructure:"hide_threshold" validate:"min=1"`
+}
+
+// ReactionConfig lists the allowed blog reactions; they are stored as field names, so they cannot contain '.' or '$'
+type ReactionConfig struct {
+	Types   []string           `mapstructure:"types" validate:"min=1,dive,required,excludesall=.$"`
+	Weights map[string]float64 `mapstructure:"weights"`
+}
+
+type ViewConfig struct {
+	DedupWindow time.Duration `mapstructure:"dedup_window" validate:"gt=0"` // repeated views by the same visitor within this window count once
+}
+
+// TrendingConfig sets how recent activity ranks blogs for sort=trending
+type TrendingConfig struct {
+	HalfLife time.Duration   `mapstructure:"half_life" validate:"gt=0"` // activity is worth half as much after each half-life
+	Window   time.Duration   `mapstructure:"window" validate:"gtfield=HalfLife"`
+	Weights  TrendingWeights `mapstructure:"weights"`
+}
+
+type TrendingWeights struct {
+	UniqueViews float64 `mapstructure:"unique_views" validate:"min=0"`
+	Likes       float64 `mapstructure:"likes" validate:"min=0"`
+	Dislikes    float64 `mapstructure:"dislikes" validate:"min=0"`
+	Comments    float64 `mapstructure:"comments" validate:"min=0"`
+	Reactions   float64 `mapstructure:"reactions" validate:"min=0"`
+}
+
+// ScoringConfig picks how the popularity score behind sort=popular is computed
+type ScoringConfig struct {
+	Strategy string         `mapstructure:"strategy" validate:"oneof=linear wilson decay"`
+	Gravity  float64        `mapstructure:"gravity" validate:"gt=0"` // how fast the decay strategy sinks older blogs
+	Weights  ScoringWeights `mapstructure:"weights"`
+}
+
+type ScoringWeights struct {
+	UniqueViews float64 `mapstructure:"unique_views" validate:"min=0"`
+	Likes       float64 `mapstructure:"likes" validate:"min=0"`
+	Dislikes    float64 `mapstructure:"dislikes" validate:"min=0"`
+	Comments    float64 `mapstructure:"comments" validate:"min=0"`
+}
+
+// QueueConfig sizes the background job worker pool and sets how failed jobs are retried
+type QueueConfig struct {
+	Workers     int           `mapstructure:"workers" validate:"min=1"`
+	MaxAttempts int           `mapstructure:"max_attempts" validate:"min=1"`
+	RetryBase   time.Duration `mapstructure:"retry_base" validate:"gt=0"`
+	RetryMax    time.Duration `mapstructure:"retry_max" validate:"gtefield=RetryBase"`
+	Lease       time.Duration `mapstructure:"lease" validate:"gt=0"` // a running job is retried by another worker after this long
+
+	RefreshWindow time.Duration `mapstructure:"refresh_window" validate:"gt=0"` // refreshes of a blog asked for within this window run once
+	RefreshBatch  int           `mapstructure:"refresh_batch" validate:"min=1"` // most blogs refreshed by one job
+}
+
 type AIConfig struct {
 	ApiKey string `mapstructure:"api_key" validate:"required"`
 }
@@ -49,8 +118,14 @@ type OAuthConfig struct {
 }
 
 type AuthConfig struct {
-	AccessTokenKey  string `mapstructure:"access_token_key" validate:"required,min=10"`
-	RefreshTokenKey string `mapstructure:"refresh_token_key" validate:"required,min=10"`
+	AccessTokenKey   string        `mapstructure:"access_token_key" validate:"required,min=10"`
+	RefreshTokenKey  string        `mapstructure:"refresh_token_key" validate:"required,min=10"`
+	SigningAlg       string        `mapstructure:"signing_alg" validate:"oneof=HS256 RS256 EdDSA"`                                  // HS256 signs access tokens with access_token_key
+	SigningKeySecret string        `mapstructure:"signing_key_secret" validate:"required_unless=SigningAlg HS256,omitempty,min=32"` // encrypts the RS256 and EdDSA private keys stored in MongoDB
+	AccessTTL        time.Duration `mapstructure:"access_ttl" validate:"gt=0"`
+	RefreshTTL       time.Duration `mapstructure:"refresh_ttl" validate:"gtfield=AccessTTL"`
+	Issuer           string        `mapstructure:"issuer" validate:"required"`
+	Audience         []string      `mapstructure:"audience" validate:"min=1"`
 }
 
 func ValidateConfig(cfg *Config) error {
@@ -81,6 +156,12 @@ func LoadConfig() (*Config, error) {
 	viper.BindEnv("mongo.url", "MONGO_URL")
 	viper.BindEnv("auth.access_token_key", "AUTH_ACCESS_TOKEN_KEY")
 	viper.BindEnv("auth.refresh_token_key", "AUTH_REFRESH_TOKEN_KEY")
+	viper.BindEnv("auth.signing_alg", "AUTH_SIGNING_ALG")
+	viper.BindEnv("auth.signing_key_secret", "AUTH_SIGNING_KEY_SECRET")
+	viper.BindEnv("auth.access_ttl", "AUTH_ACCESS_TTL")
+	viper.BindEnv("auth.refresh_ttl", "AUTH_REFRESH_TTL")
+	viper.BindEnv("auth.issuer", "AUTH_ISSUER")
+	viper.BindEnv("auth.audience", "AUTH_AUDIENCE")
 	viper.BindEnv("app.url", "APP_URL")
 	viper.BindEnv("email.app_password", "EMAIL_APP_PASSWORD")
 	viper.BindEnv("email.sender_email", "EMAIL_SENDER_EMAIL")
@@ -90,10 +171,59 @@ func LoadConfig() (*Config, error) {
 	viper.BindEnv("oauth.client_secret", "OAUTH_CLIENT_SECRET")
 	viper.BindEnv("oauth.redirect_url", "OAUTH_REDIRECT_URL")
 	viper.BindEnv("ai.api_key", "GEMINI_API_KEY")
+	viper.BindEnv("comment.max_depth", "COMMENT_MAX_DEPTH")
+	viper.BindEnv("comment.moderation", "COMMENT_MODERATION")
+	viper.BindEnv("comment.trusted_after", "COMMENT_TRUSTED_AFTER")
+	viper.BindEnv("report.hide_threshold", "REPORT_HIDE_THRESHOLD")
+	viper.BindEnv("reaction.types", "REACTION_TYPES")
+	viper.BindEnv("view.dedup_window", "VIEW_DEDUP_WINDOW")
+	viper.BindEnv("trending.half_life", "TRENDING_HALF_LIFE")
+	viper.BindEnv("trending.window", "TRENDING_WINDOW")
+	viper.BindEnv("scoring.strategy", "SCORING_STRATEGY")
+	viper.BindEnv("scoring.gravity", "SCORING_GRAVITY")
+	viper.BindEnv("queue.workers", "QUEUE_WORKERS")
+	viper.BindEnv("queue.max_attempts", "QUEUE_MAX_ATTEMPTS")
+	viper.BindEnv("queue.retry_base", "QUEUE_RETRY_BASE")
+	viper.BindEnv("queue.retry_max", "QUEUE_RETRY_MAX")
+	viper.BindEnv("queue.lease", "QUEUE_LEASE")
+	viper.BindEnv("queue.refresh_window", "QUEUE_REFRESH_WINDOW")
+	viper.BindEnv("queue.refresh_batch", "QUEUE_REFRESH_BATCH")
 
 	// Set defaults (including PORT)
 	viper.SetDefault("port", "8080")
 	viper.SetDefault("oauth.scopes", []string{"email", "profile"})
+	viper.SetDefault("auth.signing_alg", "HS256")
+	viper.SetDefault("auth.access_ttl", "15m")
+	viper.SetDefault("auth.refresh_ttl", "1440h")
+	viper.SetDefault("auth.issuer", "blog-starter-project")
+	viper.SetDefault("auth.audience", []string{"blog-starter-project"})
+	viper.SetDefault("comment.max_depth", 5)
+	viper.SetDefault("comment.moderation", "off")
+	viper.SetDefault("comment.trusted_after", 3)
+	viper.SetDefault("report.hide_threshold", 5)
+	viper.SetDefault("reaction.types", []string{"👍", "❤️", "🎉", "🤔"})
+	viper.SetDefault("view.dedup_window", "30m")
+	viper.SetDefault("reaction.weights", map[string]float64{"👍": 2, "❤️": 3, "🎉": 2.5, "🤔": 1})
+	viper.SetDefault("trending.half_life", "12h")
+	viper.SetDefault("trending.window", "168h")
+	viper.SetDefault("trending.weights.unique_views", 1)
+	viper.SetDefault("trending.weights.likes", 3)
+	viper.SetDefault("trending.weights.dislikes", 1)
+	viper.SetDefault("trending.weights.comments", 4)
+	viper.SetDefault("trending.weights.reactions", 2)
+	viper.SetDefault("scoring.strategy", "linear")
+	viper.SetDefault("scoring.gravity", 1.8)
+	viper.SetDefault("scoring.weights.unique_views", 0.5)
+	viper.SetDefault("scoring.weights.likes", 2)
+	viper.SetDefault("scoring.weights.dislikes", 1)
+	viper.SetDefault("scoring.weights.comments", 1.5)
+	viper.SetDefault("queue.workers", 4)
+	viper.SetDefault("queue.max_attempts", 5)
+	viper.SetDefault("queue.retry_base", "10s")
+	viper.SetDefault("queue.retry_max", "10m")
+	viper.SetDefault("queue.lease", "1m")
+	viper.SetDefault("queue.refresh_window", "2s")
+	viper.SetDefault("queue.refresh_batch", 100)
 
 	// Unmarshal into struct
 	var cfg Config
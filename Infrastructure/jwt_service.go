@@ -2,108 +2,360 @@ package infrastructure
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"log"
+	"strings"
 	"time"
-	"fmt"
 
 	"github.com/gedyzed/blog-starter-project/Domain"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// JWTTokenService issues the tokens of sessions. Access tokens are signed by the key ring, or with the
+// access key when it uses HS256; refresh tokens are always HS256 with the refresh key, as only we verify them.
 type JWTTokenService struct {
-	repo       domain.ITokenRepo
-	accessKey  []byte
-	refreshKey []byte
-	accessTTL  time.Duration
-	refreshTTL time.Duration
+	repo    domain.ITokenRepo
+	users   domain.IUserRepository
+	revoked domain.IRevokedTokenRepo
+	events  domain.ISecurityEventRepo
+	keys    *KeyRing
+	policy  TokenPolicy
 }
 
-func NewJWTTokenService(repo domain.ITokenRepo, accessKey, refreshKey string, accessTTL, refreshTTL time.Duration) *JWTTokenService {
+// TokenPolicy sets how tokens are signed, who they are for and how long they live
+type TokenPolicy struct {
+	AccessKey  string // signs access tokens with HS256
+	RefreshKey string
+	AccessTTL  time.Duration // short, so role changes reach the claims quickly
+	RefreshTTL time.Duration
+	Issuer     string
+	Audience   []string
+}
+
+func NewJWTTokenService(repo domain.ITokenRepo, users domain.IUserRepository, revoked domain.IRevokedTokenRepo, events domain.ISecurityEventRepo, keys *KeyRing, policy TokenPolicy) *JWTTokenService {
 	return &JWTTokenService{
-		repo,
-		[]byte(accessKey),
-		[]byte(refreshKey),
-		accessTTL,
-		refreshTTL,
+		repo:    repo,
+		users:   users,
+		revoked: revoked,
+		events:  events,
+		keys:    keys,
+		policy:  policy,
 	}
 }
 
-func (s *JWTTokenService) signJWT(userID string, key []byte, ttl time.Duration) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   userID,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+// errTokenExpired tells an expired token apart from an invalid one
+var errTokenExpired = errors.New("token has expired")
+
+// tokenClaims are the claims of both token kinds; sid is the session the token was issued to
+// and gen counts its rotations. jti identifies the token so it can be revoked.
+// Access tokens also carry the user's role and scopes, so they are authorized without a database lookup.
+type tokenClaims struct {
+	SessionID  string `json:"sid"`
+	Generation int    `json:"gen"`
+	Role       string `json:"role,omitempty"`
+	Scope      string `json:"scope,omitempty"` // space separated, as in RFC 8693
+	jwt.RegisteredClaims
+}
+
+func (s *JWTTokenService) newClaims(session *domain.Token, tokenID string, ttl time.Duration) tokenClaims {
+	return tokenClaims{
+		SessionID:  session.ID.Hex(),
+		Generation: session.Generation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    s.policy.Issuer,
+			Audience:  s.policy.Audience,
+			Subject:   session.UserID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
+}
 
+func (s *JWTTokenService) signJWT(claims tokenClaims, key []byte) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(key)
 }
 
-func (s *JWTTokenService) verifyJWT(tokenString string, key []byte) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
-		fmt.Println("key : ", key)
-		return key, nil
-	})
+func (s *JWTTokenService) signAccessToken(ctx context.Context, claims tokenClaims) (string, error) {
+	if s.keys.algorithm == SigningHS256 {
+		return s.signJWT(claims, []byte(s.policy.AccessKey))
+	}
+	return s.keys.sign(ctx, claims)
+}
+
+func (s *JWTTokenService) verifyJWT(tokenString string, keyFunc jwt.Keyfunc, algorithm string) (*tokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, keyFunc,
+		jwt.WithValidMethods([]string{algorithm}),
+		jwt.WithIssuer(s.policy.Issuer),
+		jwt.WithAudience(s.policy.Audience...),
+	)
 
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, errTokenExpired
+	}
 	if err != nil {
-		return "", domain.ErrInvalidToken
+		return nil, domain.ErrInvalidToken
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-		return claims.Subject, nil
+	if claims, ok := token.Claims.(*tokenClaims); ok && token.Valid {
+		return claims, nil
 	}
 	
-	return "", domain.ErrInvalidToken
+	return nil, domain.ErrInvalidToken
 }
 
-func (s *JWTTokenService) GenerateTokens(ctx context.Context, userID string) (*domain.Token, error) {
-	accessToken, err := s.signJWT(userID, s.accessKey, s.accessTTL)
+func (s *JWTTokenService) verifyRefreshToken(tokenString string) (*tokenClaims, error) {
+	return s.verifyJWT(tokenString, secret([]byte(s.policy.RefreshKey)), SigningHS256)
+}
+
+func (s *JWTTokenService) verifyAccessToken(ctx context.Context, tokenString string) (*tokenClaims, error) {
+	if s.keys.algorithm == SigningHS256 {
+		return s.verifyJWT(tokenString, secret([]byte(s.policy.AccessKey)), SigningHS256)
+	}
+	return s.verifyJWT(tokenString, func(token *jwt.Token) (any, error) {
+		return s.keys.verificationKey(ctx, token)
+	}, s.keys.algorithm)
+}
+
+func secret(key []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		return key, nil
+	}
+}
+
+// GenerateTokens signs the user in on a new device
+func (s *JWTTokenService) GenerateTokens(ctx context.Context, userID string, device domain.Device) (*domain.Token, error) {
+	session := domain.Token{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		UserAgent: device.UserAgent,
+		IP:        device.IP,
+	}
+
+	if err := s.sign(ctx, &session); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// sign issues new access and refresh tokens to the session, with the user's current role
+func (s *JWTTokenService) sign(ctx context.Context, session *domain.Token) error {
+	session.Generation++
+	accessTokenID := rand.Text()
+
+	user, err := s.users.Get(ctx, session.UserID)
+	if err != nil {
+		return err
+	}
+
+	accessClaims := s.newClaims(session, accessTokenID, s.policy.AccessTTL)
+	accessClaims.Role = user.Role
+	accessClaims.Scope = strings.Join(domain.ScopesForRole(user.Role), " ")
+
+	accessToken, err := s.signAccessToken(ctx, accessClaims)
 	if err != nil {
+		return err
+	}
+
+	refreshToken, err := s.signJWT(s.newClaims(session, rand.Text(), s.policy.RefreshTTL), []byte(s.policy.RefreshKey))
+	if err != nil {
+		return err
+	}
+
+	session.AccessTokenID = accessTokenID
+	session.AccessToken = accessToken
+	session.RefreshToken = refreshToken
+	session.AccessExpiry = time.Now().Add(s.policy.AccessTTL)
+	session.RefreshExpiry = time.Now().Add(s.policy.RefreshTTL)
+	return nil
+}
+
+// RefreshTokens rotates the tokens of the session the refresh token belongs to. A session is a token family:
+// each refresh replaces its refresh token, so a refresh token is valid once, and revokes its previous access token. Presenting one that was already
+// rotated means it leaked, so the whole session is revoked and a security event is recorded for the user.
+func (s *JWTTokenService) RefreshTokens(ctx context.Context, userID, refreshToken string, device domain.Device) (*domain.Token, error) {
+	claims, err := s.verifyRefreshToken(refreshToken)
+	if errors.Is(err, errTokenExpired) {
+		return nil, domain.ErrExpiredRefreshToken
+	}
+	if err != nil || claims.Subject != userID {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	session, err := s.repo.FindByID(ctx, claims.SessionID)
+	if err != nil || session.UserID != userID {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	if session.RefreshToken != refreshToken {
+		s.revokeFamily(ctx, session, device)
+		return nil, domain.ErrRefreshTokenReused
+	}
+
+	previous := *session
+	if err := s.sign(ctx, session); err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.signJWT(userID, s.refreshKey, s.refreshTTL)
+	err = s.repo.Rotate(ctx, session, refreshToken)
+	if errors.Is(err, domain.ErrTokenNotFound) {
+		// another request exchanged the same refresh token first
+		s.revokeFamily(ctx, session, device)
+		return nil, domain.ErrRefreshTokenReused
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	tokens := domain.Token{
-		UserID:        userID,
-		AccessToken:   accessToken,
-		RefreshToken:  refreshToken,
-		AccessExpiry:  time.Now().Add(s.accessTTL),
-		RefreshExpiry: time.Now().Add(s.refreshTTL),
+	if err := s.revokeAccessToken(ctx, &previous); err != nil {
+		log.Println("Failed to revoke the previous access token of session", claims.SessionID, err)
 	}
 
-	if err := s.repo.Save(ctx, &tokens); err != nil {
-		return nil, err
+	return session, nil
+}
+
+// revokeFamily ends a session whose refresh token was reused and records it for the user
+func (s *JWTTokenService) revokeFamily(ctx context.Context, session *domain.Token, device domain.Device) {
+	sessionID := session.ID.Hex()
+	if err := s.endSession(ctx, session); err != nil {
+		log.Println("Failed to revoke session", sessionID, err)
 	}
 
-	return &tokens, nil
+	userID, err := primitive.ObjectIDFromHex(session.UserID)
+	if err != nil {
+		return
+	}
+	event := domain.SecurityEvent{
+		UserID:    userID,
+		Type:      domain.SecurityEventRefreshTokenReuse,
+		SessionID: sessionID,
+		UserAgent: device.UserAgent,
+		IP:        device.IP,
+	}
+	if err := s.events.Create(ctx, &event); err != nil {
+		log.Println("Failed to record security event for user", session.UserID, err)
+	}
 }
 
-func (s *JWTTokenService) RefreshTokens(ctx context.Context, refreshToken string) (*domain.Token, error) {
-	userID, err := s.verifyJWT(refreshToken, s.refreshKey)
+func (s *JWTTokenService) VerifyAccessToken(ctx context.Context, tokenString string) (string, error) {
+	claims, err := s.ParseAccessToken(ctx, tokenString)
 	if err != nil {
-		return nil, domain.ErrInvalidRefreshToken
+		return "", err
 	}
+	return claims.UserID, nil
+}
 
-	tokens, err := s.repo.FindByUserID(ctx, userID)
+func (s *JWTTokenService) ParseAccessToken(ctx context.Context, tokenString string) (*domain.AccessClaims, error) {
+	claims, err := s.verifyAccessToken(ctx, tokenString)
 	if err != nil {
-		return nil, domain.ErrInvalidRefreshToken
+		return nil, domain.ErrInvalidToken
 	}
+	return &domain.AccessClaims{
+		UserID:    claims.Subject,
+		SessionID: claims.SessionID,
+		TokenID:   claims.ID,
+		Role:      claims.Role,
+		Scopes:    strings.Fields(claims.Scope),
+	}, nil
+}
 
-	if tokens.RefreshToken != refreshToken {
-		return nil, domain.ErrInvalidRefreshToken
+func (s *JWTTokenService) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	if tokenID == "" {
+		// tokens issued before they had an ID cannot be revoked one by one
+		return false, nil
 	}
+	return s.revoked.IsRevoked(ctx, tokenID)
+}
 
-	if tokens.RefreshExpiry.Before(time.Now()) {
-		_ = s.repo.DeleteByUserID(ctx, userID)
-		return nil, domain.ErrInvalidRefreshToken
+// RevokeSession ends one session of the user and revokes its access token
+func (s *JWTTokenService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.repo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
 	}
+	if session.UserID != userID {
+		return domain.ErrTokenNotFound
+	}
+
+	return s.endSession(ctx, session)
+}
+
+// RevokeSessions ends every session of the user except keepSessionID, which may be empty to end them all,
+// and returns how many were ended
+func (s *JWTTokenService) RevokeSessions(ctx context.Context, userID, keepSessionID string) (int, error) {
+	sessions, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for i := range sessions {
+		if sessions[i].ID.Hex() == keepSessionID {
+			continue
+		}
+		if err := s.endSession(ctx, &sessions[i]); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// RevokeAccessTokens revokes the access tokens of every session of the user but keeps the sessions,
+// so clients have to refresh their tokens
+func (s *JWTTokenService) RevokeAccessTokens(ctx context.Context, userID string) error {
+	sessions, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if err := s.revokeAccessToken(ctx, &sessions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *JWTTokenService) endSession(ctx context.Context, session *domain.Token) error {
+	if err := s.revokeAccessToken(ctx, session); err != nil {
+		return err
+	}
+
+	err := s.repo.DeleteByID(ctx, session.UserID, session.ID.Hex())
+	if err != nil && !errors.Is(err, domain.ErrTokenNotFound) {
+		return err
+	}
+	return nil
+}
+
+// revokeAccessToken denies the current access token of the session until it expires
+func (s *JWTTokenService) revokeAccessToken(ctx context.Context, session *domain.Token) error {
+	if session.AccessTokenID == "" || session.AccessExpiry.Before(time.Now()) {
+		return nil
+	}
+
+	return s.revoked.Revoke(ctx, &domain.RevokedToken{
+		TokenID:   session.AccessTokenID,
+		ExpiresAt: session.AccessExpiry,
+	})
+}
 
-	return s.GenerateTokens(ctx, userID)
+// JWKS returns the public keys other services verify access tokens with; it is empty with HS256
+func (s *JWTTokenService) JWKS(ctx context.Context) (*domain.JWKS, error) {
+	return s.keys.JWKS(ctx)
 }
 
-func (s *JWTTokenService) VerifyAccessToken(tokenString string) (string, error) {
-	return s.verifyJWT(tokenString, s.accessKey)
+// RotateSigningKey starts signing access tokens with a new key; tokens signed with the previous key stay valid
+func (s *JWTTokenService) RotateSigningKey(ctx context.Context) (*domain.SigningKey, error) {
+	return s.keys.Rotate(ctx)
 }
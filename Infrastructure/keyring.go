@@ -0,0 +1,348 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms of access tokens
+const (
+	SigningHS256 = "HS256" // shared secret from the config; tokens can only be verified by this service
+	SigningRS256 = "RS256" // RSA key pairs, published in the JWKS
+	SigningEdDSA = "EdDSA" // Ed25519 key pairs, published in the JWKS
+)
+
+const (
+	// keyReloadInterval is how often the keys are reloaded, so servers pick up keys rotated by another server
+	keyReloadInterval = time.Minute
+	rsaKeyBits        = 2048
+	// keyEncryptionInfo separates the key that encrypts private keys from other keys derived from the same secret
+	keyEncryptionInfo = "blog-starter-project signing key encryption"
+)
+
+// loadedKey is a signing key with its PEM blocks parsed
+type loadedKey struct {
+	domain.SigningKey
+	signer crypto.Signer
+	public crypto.PublicKey
+}
+
+// KeyRing holds the key pairs access tokens are signed and verified with. The keys are stored in MongoDB
+// so every server shares them; after a rotation retired keys keep verifying until the tokens they signed expire.
+// Private keys are encrypted before they are stored, so reading the database is not enough to sign tokens.
+type KeyRing struct {
+	repo      domain.ISigningKeyRepo
+	algorithm string
+	retention time.Duration
+	sealer    cipher.AEAD // encrypts private keys with a key derived from the configured secret
+
+	mu       sync.RWMutex
+	keys     map[string]*loadedKey
+	active   *loadedKey
+	loadedAt time.Time
+}
+
+// NewKeyRing returns a key ring for the algorithm; retention is how long a retired key keeps verifying,
+// which has to cover the lifetime of access tokens. The secret encrypts the stored private keys and is
+// only needed with RS256 and EdDSA.
+func NewKeyRing(repo domain.ISigningKeyRepo, algorithm string, retention time.Duration, secret string) (*KeyRing, error) {
+	ring := &KeyRing{
+		repo:      repo,
+		algorithm: algorithm,
+		retention: retention + keyReloadInterval,
+		keys:      make(map[string]*loadedKey),
+	}
+	if algorithm == SigningHS256 {
+		return ring, nil
+	}
+
+	if secret == "" {
+		return nil, errors.New("a secret to encrypt signing keys is required with " + algorithm)
+	}
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, keyEncryptionInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive signing key encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if ring.sealer, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+
+	return ring, nil
+}
+
+// Load reads the keys and creates a signing key when there is none for the algorithm yet
+func (k *KeyRing) Load(ctx context.Context) error {
+	if k.algorithm == SigningHS256 {
+		return nil
+	}
+
+	if err := k.reload(ctx); err != nil {
+		return err
+	}
+
+	k.mu.RLock()
+	active := k.active
+	k.mu.RUnlock()
+
+	if active == nil || active.Algorithm != k.algorithm {
+		_, err := k.Rotate(ctx)
+		return err
+	}
+	return nil
+}
+
+// Rotate creates a new signing key; tokens signed with the previous key stay valid
+func (k *KeyRing) Rotate(ctx context.Context) (*domain.SigningKey, error) {
+	if k.algorithm == SigningHS256 {
+		return nil, domain.ErrSymmetricSigning
+	}
+
+	key, err := k.generateSigningKey(k.algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := k.repo.Rotate(ctx, key, time.Now().Add(k.retention)); err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	if err := k.reload(ctx); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// JWKS returns the public keys that verify access tokens
+func (k *KeyRing) JWKS(ctx context.Context) (*domain.JWKS, error) {
+	jwks := &domain.JWKS{Keys: []domain.JWK{}}
+	if k.algorithm == SigningHS256 {
+		return jwks, nil
+	}
+
+	if err := k.reloadIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		jwk := domain.JWK{Use: "sig", Alg: key.Algorithm, Kid: key.ID}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks, nil
+}
+
+// sign signs the claims with the active key and names it in the kid header
+func (k *KeyRing) sign(ctx context.Context, claims jwt.Claims) (string, error) {
+	if err := k.reloadIfStale(ctx); err != nil {
+		return "", err
+	}
+
+	k.mu.RLock()
+	active := k.active
+	k.mu.RUnlock()
+
+	if active == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(active.Algorithm), claims)
+	token.Header["kid"] = active.ID
+	return token.SignedString(active.signer)
+}
+
+// verificationKey returns the public key named by the token's kid header; unknown kids make the keys reload,
+// as another server may have rotated them
+func (k *KeyRing) verificationKey(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+
+	if !ok {
+		if err := k.reloadIfOlder(ctx, time.Second); err != nil {
+			return nil, err
+		}
+		k.mu.RLock()
+		key, ok = k.keys[kid]
+		k.mu.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("token is signed with %s but key %q is %s", token.Method.Alg(), kid, key.Algorithm)
+	}
+
+	return key.public, nil
+}
+
+func (k *KeyRing) reloadIfStale(ctx context.Context) error {
+	return k.reloadIfOlder(ctx, keyReloadInterval)
+}
+
+func (k *KeyRing) reloadIfOlder(ctx context.Context, age time.Duration) error {
+	k.mu.RLock()
+	fresh := time.Since(k.loadedAt) < age
+	k.mu.RUnlock()
+
+	if fresh {
+		return nil
+	}
+	return k.reload(ctx)
+}
+
+func (k *KeyRing) reload(ctx context.Context) error {
+	stored, err := k.repo.GetKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make(map[string]*loadedKey, len(stored))
+	var active *loadedKey
+	for _, key := range stored {
+		loaded, err := k.parseSigningKey(key)
+		if err != nil {
+			return fmt.Errorf("failed to parse signing key %q: %w", key.ID, err)
+		}
+		keys[key.ID] = loaded
+		// keys are sorted newest first, so the first active one is the newest
+		if active == nil && key.Active {
+			active = loaded
+		}
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.active = active
+	k.loadedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KeyRing) generateSigningKey(algorithm string) (*domain.SigningKey, error) {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	switch algorithm {
+	case SigningRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case SigningEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	private, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+	public, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key := &domain.SigningKey{
+		ID:        rand.Text(),
+		Algorithm: algorithm,
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})),
+	}
+	key.PrivateKey = k.seal(key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}))
+	return key, nil
+}
+
+func (k *KeyRing) parseSigningKey(key domain.SigningKey) (*loadedKey, error) {
+	privatePEM, err := k.open(key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+
+	return &loadedKey{SigningKey: key, signer: signer, public: signer.Public()}, nil
+}
+
+// seal encrypts the private key; the kid and algorithm are authenticated with it, so a stored private key
+// cannot be moved to another key record
+func (k *KeyRing) seal(key *domain.SigningKey, privatePEM []byte) string {
+	nonce := make([]byte, k.sealer.NonceSize())
+	rand.Read(nonce)
+
+	sealed := k.sealer.Seal(nonce, nonce, privatePEM, sealedKeyData(key))
+	return base64.StdEncoding.EncodeToString(sealed)
+}
+
+// open decrypts a private key sealed by seal
+func (k *KeyRing) open(key domain.SigningKey) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(key.PrivateKey)
+	if err != nil || len(sealed) < k.sealer.NonceSize() {
+		return nil, errors.New("private key is not encrypted")
+	}
+
+	nonce, ciphertext := sealed[:k.sealer.NonceSize()], sealed[k.sealer.NonceSize():]
+	privatePEM, err := k.sealer.Open(nil, nonce, ciphertext, sealedKeyData(&key))
+	if err != nil {
+		return nil, errors.New("private key cannot be decrypted, the signing key secret may have changed")
+	}
+	return privatePEM, nil
+}
+
+func sealedKeyData(key *domain.SigningKey) []byte {
+	return []byte(key.ID + "." + key.Algorithm)
+}
@@ -0,0 +1,225 @@
+package infrastructure
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testKeySecret = "a-signing-key-secret-of-at-least-32-characters"
+
+// memorySigningKeyRepo keeps signing keys in memory the way the MongoDB repository stores them
+type memorySigningKeyRepo struct {
+	mu   sync.Mutex
+	keys []domain.SigningKey
+}
+
+func (r *memorySigningKeyRepo) GetKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]domain.SigningKey, 0, len(r.keys))
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if key := r.keys[i]; key.ExpiresAt == nil || key.ExpiresAt.After(time.Now()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *memorySigningKeyRepo) Rotate(ctx context.Context, key *domain.SigningKey, retireAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].Active {
+			r.keys[i].Active = false
+			r.keys[i].ExpiresAt = &retireAt
+		}
+	}
+	key.Active = true
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *memorySigningKeyRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func newTestKeyRing(t *testing.T, repo domain.ISigningKeyRepo, algorithm string) *KeyRing {
+	t.Helper()
+
+	ring, err := NewKeyRing(repo, algorithm, time.Hour, testKeySecret)
+	if err != nil {
+		t.Fatalf("NewKeyRing() returned %v", err)
+	}
+	if err := ring.Load(context.Background()); err != nil {
+		t.Fatalf("Load() returned %v", err)
+	}
+	return ring
+}
+
+func signTestToken(t *testing.T, ring *KeyRing, subject string) string {
+	t.Helper()
+
+	token, err := ring.sign(context.Background(), jwt.RegisteredClaims{Subject: subject})
+	if err != nil {
+		t.Fatalf("sign() returned %v", err)
+	}
+	return token
+}
+
+func verifyTestToken(ring *KeyRing, token string) (string, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (any, error) {
+		return ring.verificationKey(context.Background(), token)
+	}, jwt.WithValidMethods([]string{ring.algorithm}))
+	return claims.Subject, err
+}
+
+func TestKeyRingSignsAndVerifiesAcrossRotation(t *testing.T) {
+	for _, algorithm := range []string{SigningRS256, SigningEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			ctx := context.Background()
+			ring := newTestKeyRing(t, &memorySigningKeyRepo{}, algorithm)
+
+			before := signTestToken(t, ring, "before")
+			if _, err := ring.Rotate(ctx); err != nil {
+				t.Fatalf("Rotate() returned %v", err)
+			}
+			after := signTestToken(t, ring, "after")
+
+			// tokens signed before the rotation keep verifying until the retired key expires
+			for token, want := range map[string]string{before: "before", after: "after"} {
+				got, err := verifyTestToken(ring, token)
+				if err != nil {
+					t.Fatalf("token of %q does not verify: %v", want, err)
+				}
+				if got != want {
+					t.Fatalf("verified subject = %q, want %q", got, want)
+				}
+			}
+
+			if kidOf(t, before) == kidOf(t, after) {
+				t.Fatal("tokens signed before and after the rotation name the same key")
+			}
+
+			jwks, err := ring.JWKS(ctx)
+			if err != nil {
+				t.Fatalf("JWKS() returned %v", err)
+			}
+			if len(jwks.Keys) != 2 {
+				t.Fatalf("JWKS() has %d keys, want the active and the retired key", len(jwks.Keys))
+			}
+		})
+	}
+}
+
+func TestKeyRingPicksUpKeysRotatedByAnotherServer(t *testing.T) {
+	repo := &memorySigningKeyRepo{}
+	ring := newTestKeyRing(t, repo, SigningEdDSA)
+	other := newTestKeyRing(t, repo, SigningEdDSA)
+
+	if _, err := other.Rotate(context.Background()); err != nil {
+		t.Fatalf("Rotate() returned %v", err)
+	}
+	token := signTestToken(t, other, "rotated elsewhere")
+
+	// an unknown kid reloads the keys at most once a second, so the last load is made to look older
+	ring.loadedAt = time.Time{}
+	if _, err := verifyTestToken(ring, token); err != nil {
+		t.Fatalf("token signed with a key rotated by another server does not verify: %v", err)
+	}
+}
+
+func TestKeyRingRejectsUnknownKid(t *testing.T) {
+	ring := newTestKeyRing(t, &memorySigningKeyRepo{}, SigningEdDSA)
+	stranger := newTestKeyRing(t, &memorySigningKeyRepo{}, SigningEdDSA)
+
+	token := signTestToken(t, stranger, "stranger")
+	_, err := verifyTestToken(ring, token)
+	if err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Fatalf("verifying a token of an unknown key returned %v, want an unknown signing key error", err)
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{Subject: "no kid"})
+	unsigned.Header["kid"] = ""
+	if _, err := ring.verificationKey(context.Background(), unsigned); err == nil {
+		t.Fatal("verificationKey() of a token without a kid returned no error")
+	}
+}
+
+func TestKeyRingRejectsAlgorithmOfAnotherKey(t *testing.T) {
+	repo := &memorySigningKeyRepo{}
+	ring := newTestKeyRing(t, repo, SigningEdDSA)
+	kid := kidOf(t, signTestToken(t, ring, "subject"))
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{Subject: "subject"})
+	forged.Header["kid"] = kid
+	if _, err := ring.verificationKey(context.Background(), forged); err == nil {
+		t.Fatal("verificationKey() accepted a token whose algorithm does not match its key")
+	}
+}
+
+func TestKeyRingEncryptsStoredPrivateKeys(t *testing.T) {
+	repo := &memorySigningKeyRepo{}
+	newTestKeyRing(t, repo, SigningRS256)
+
+	stored, _ := repo.GetKeys(context.Background())
+	if len(stored) != 1 {
+		t.Fatalf("stored %d keys, want 1", len(stored))
+	}
+	if strings.Contains(stored[0].PrivateKey, "PRIVATE KEY") {
+		t.Fatal("private key is stored as plain PEM")
+	}
+
+	// a server with another secret cannot use the stored keys
+	wrongSecret, err := NewKeyRing(repo, SigningRS256, time.Hour, strings.Repeat("x", 32))
+	if err != nil {
+		t.Fatalf("NewKeyRing() returned %v", err)
+	}
+	if err := wrongSecret.Load(context.Background()); err == nil {
+		t.Fatal("Load() with the wrong secret returned no error")
+	}
+
+	// a private key moved to another key record does not decrypt
+	repo.keys = append(repo.keys, domain.SigningKey{
+		ID:         "moved",
+		Algorithm:  SigningRS256,
+		PrivateKey: stored[0].PrivateKey,
+		Active:     true,
+	})
+	ring, err := NewKeyRing(repo, SigningRS256, time.Hour, testKeySecret)
+	if err != nil {
+		t.Fatalf("NewKeyRing() returned %v", err)
+	}
+	if err := ring.Load(context.Background()); err == nil {
+		t.Fatal("Load() accepted a private key moved to another kid")
+	}
+}
+
+func TestNewKeyRingRequiresSecret(t *testing.T) {
+	if _, err := NewKeyRing(&memorySigningKeyRepo{}, SigningEdDSA, time.Hour, ""); err == nil {
+		t.Fatal("NewKeyRing() without a secret returned no error")
+	}
+	if _, err := NewKeyRing(&memorySigningKeyRepo{}, SigningHS256, time.Hour, ""); err != nil {
+		t.Fatalf("NewKeyRing() with HS256 returned %v, want no secret to be needed", err)
+	}
+}
+
+func kidOf(t *testing.T, token string) string {
+	t.Helper()
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() returned %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
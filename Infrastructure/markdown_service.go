@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"bytes"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+type markdownService struct {
+	blogRenderer    goldmark.Markdown
+	commentRenderer goldmark.Markdown
+	blogPolicy      *bluemonday.Policy
+	commentPolicy   *bluemonday.Policy
+}
+
+func NewMarkdownService() domain.IMarkdownService {
+	// comments only keep inline formatting, links, lists, quotes and code
+	commentPolicy := bluemonday.NewPolicy()
+	commentPolicy.AllowElements("p", "br", "strong", "b", "em", "i", "del", "code", "pre", "blockquote", "ul", "ol", "li")
+	commentPolicy.AllowAttrs("href").OnElements("a")
+	commentPolicy.AllowStandardURLs()
+	commentPolicy.RequireNoFollowOnLinks(true)
+	commentPolicy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	blogPolicy := bluemonday.UGCPolicy()
+	blogPolicy.RequireNoFollowOnLinks(true)
+
+	return &markdownService{
+		blogRenderer:    goldmark.New(goldmark.WithExtensions(extension.GFM)),
+		commentRenderer: goldmark.New(goldmark.WithExtensions(extension.Strikethrough, extension.Linkify)),
+		blogPolicy:      blogPolicy,
+		commentPolicy:   commentPolicy,
+	}
+}
+
+// RenderBlog renders blog Markdown to HTML and strips scripts and unsafe attributes
+func (s *markdownService) RenderBlog(source string) (string, error) {
+	return render(s.blogRenderer, s.blogPolicy, source)
+}
+
+// RenderComment renders the restricted comment Markdown subset to sanitized HTML
+func (s *markdownService) RenderComment(source string) (string, error) {
+	return render(s.commentRenderer, s.commentPolicy, source)
+}
+
+func render(md goldmark.Markdown, policy *bluemonday.Policy, source string) (string, error) {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
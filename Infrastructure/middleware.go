@@ -1,8 +1,10 @@
 package infrastructure
 
 import (
-	"fmt"
+	"context"
+	"log"
 	"net/http"
+	"slices"
 	"strings"
 
 	domain "github.com/gedyzed/blog-starter-project/Domain"
@@ -36,26 +38,66 @@ func (m *AuthMiddleware) IsLogin(c *gin.Context) {
 	}
 
 	token := strings.TrimPrefix(header, "Bearer ")
-	var userID string
 
-	// First try local JWT verification
-	uid, err := m.TokenService.VerifyAccessToken(token)
-	if err == nil {
-		userID = uid
-	} else {
-		
-		// Fallback to Google OAuth2 verification
-		resolvedID, err := m.oauthService.VerifyGoogleIDToken(ctx, token)
+	claims, err := m.verify(ctx, token)
+	if err != nil {
+		rejectToken(c, err)
+		return
+	}
+
+	c.Set("userID", claims.UserID)
+	c.Set("sessionID", claims.SessionID)
+	c.Next()
+}
+
+// verify checks a local JWT first and falls back to the stored Google OAuth2 sessions.
+// JWTs that were revoked before they expired are rejected.
+func (m *AuthMiddleware) verify(ctx context.Context, token string) (*domain.AccessClaims, error) {
+	if claims, err := m.TokenService.ParseAccessToken(ctx, token); err == nil {
+		revoked, err := m.TokenService.IsRevoked(ctx, claims.TokenID)
 		if err != nil {
-			c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": domain.ErrInvalidToken.Error()})
-			c.Abort()
-			return
+			log.Println("Failed to check token revocation:", err)
+			return nil, domain.ErrInternalServer
 		}
+		if revoked {
+			return nil, domain.ErrTokenRevoked
+		}
+		return claims, nil
+	}
+
+	session, err := m.oauthService.VerifyGoogleIDToken(ctx, token)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	return &domain.AccessClaims{UserID: session.UserID, SessionID: session.ID.Hex()}, nil
+}
+
+func rejectToken(c *gin.Context, err error) {
+	if err == domain.ErrInternalServer {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	} else {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	}
+	c.Abort()
+}
 
-		userID = resolvedID
+// OptionalLogin sets the userID when a valid token is sent but lets anonymous requests through
+func (m *AuthMiddleware) OptionalLogin(c *gin.Context) {
+
+	ctx := c.Request.Context()
+	header := c.GetHeader("Authorization")
+	if header == "" || !strings.HasPrefix(header, "Bearer ") {
+		c.Next()
+		return
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+
+	if claims, err := m.verify(ctx, token); err == nil {
+		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
 	}
 
-	c.Set("userID", userID)
 	c.Next()
 }
 
@@ -77,37 +119,31 @@ func (m *AuthMiddleware) IsLoginWithRole() gin.HandlerFunc {
 			return
 		}
 
-		var UserID string
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 
-		userID, err := m.TokenService.VerifyAccessToken(token)
-		if err == nil {
-			UserID  = userID
-		} else {
+		claims, err := m.verify(ctx, token)
+		if err != nil {
+			rejectToken(c, err)
+			return
+		}
 
-			// Fallback to Google OAuth2 verification
-			resolvedID, err := m.oauthService.VerifyGoogleIDToken(ctx, token)
-			fmt.Println("userID : ", resolvedID)
+		// JWTs carry the role; Google sessions get it from the database
+		if claims.Role == "" {
+			user, err := m.userUsecase.FindByUserID(ctx, claims.UserID)
 			if err != nil {
-				c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": domain.ErrInvalidToken.Error()})
+				c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 				c.Abort()
-				return
-		}
-		 UserID = resolvedID
-		}
-
-		// Get user role from database
-		user, err := m.userUsecase.FindByUserID(ctx, UserID)
-		if err != nil {
-			c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			c.Abort()
-			return	
+				return	
+			}
+			claims.Role = user.Role
+			claims.Scopes = domain.ScopesForRole(user.Role)
 		}
 
-
 		// Set both userID and role in context
-		c.Set("userID", UserID)
-		c.Set("role", user.Role)
+		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("role", claims.Role)
+		c.Set("scopes", claims.Scopes)
 		c.Next()
 	}
 }
@@ -131,3 +167,19 @@ func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireScope lets a request through only when its access token grants the scope; it runs after IsLoginWithRole
+func (m *AuthMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, _ := c.Get("scopes")
+		granted, _ := scopes.([]string)
+
+		if !slices.Contains(granted, scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access token lacks the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
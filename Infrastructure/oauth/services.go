@@ -21,16 +21,23 @@ func NewOAuthServices(cfg *oauth2.Config,  uc *usecases.UserUsecases) domain.IOA
 	return &OAuthServices{config:cfg, userUsecase: uc}
 }
 
-func (os OAuthServices) VerifyGoogleIDToken(ctx context.Context, accessToken string)(string, error){
+// VerifyGoogleIDToken returns the session a Google access token was stored for
+func (os OAuthServices) VerifyGoogleIDToken(ctx context.Context, accessToken string)(*domain.Token, error){
 	
-	userID, err := os.userUsecase.GetToken(ctx, accessToken)
+	session, err := os.userUsecase.GetToken(ctx, accessToken)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return userID, nil
+	return session, nil
 }
 
-func (os OAuthServices) RefreshToken(ctx context.Context, token *domain.Token)(*domain.Token, error){
+// RefreshToken refreshes the Google tokens of the session the refresh token was stored for
+func (os OAuthServices) RefreshToken(ctx context.Context, input *domain.Token)(*domain.Token, error){
+
+	token, err := os.userUsecase.GetTokenByRefreshToken(ctx, input.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
 
 	expiredToken := &oauth2.Token{
 		RefreshToken: token.RefreshToken,
@@ -70,7 +77,8 @@ func (os OAuthServices) ResolveUserID(ctx context.Context, email string)(string,
 	return userID, nil
 }
 
-func (os OAuthServices) OAuthCallBack(ctx context.Context, code string) (*domain.Token, error){
+// OAuthCallBack signs the Google user in, starting a new session for the device
+func (os OAuthServices) OAuthCallBack(ctx context.Context, code string, device domain.Device) (*domain.Token, error){
 
 	got, err := os.config.Exchange(ctx, code)
 	if err != nil {
@@ -85,6 +93,8 @@ func (os OAuthServices) OAuthCallBack(ctx context.Context, code string) (*domain
 		RefreshToken:  got.RefreshToken,
 		AccessExpiry:  got.Expiry,
 		RefreshExpiry: now.Add(refreshTTL),
+		UserAgent:     device.UserAgent,
+		IP:            device.IP,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
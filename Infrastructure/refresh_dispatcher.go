@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+// refreshFlushTimeout bounds how long storing the refresh jobs of one window may take
+const refreshFlushTimeout = 10 * time.Second
+
+// RefreshDispatcher coalesces popularity refreshes: a blog enqueued several times within one window
+// is refreshed once, and the blogs of a window are stored as batch jobs on the job queue.
+// Blogs waiting for their window to end are only kept in memory.
+type RefreshDispatcher struct {
+	queue     *JobQueue
+	window    time.Duration
+	batchSize int
+
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+
+	enqueued  atomic.Int64
+	coalesced atomic.Int64
+	processed atomic.Int64
+}
+
+func NewRefreshDispatcher(queue *JobQueue, window time.Duration, batchSize int) *RefreshDispatcher {
+	return &RefreshDispatcher{
+		queue:     queue,
+		window:    window,
+		batchSize: batchSize,
+		queued:    make(map[string]bool),
+	}
+}
+
+// Enqueue asks for a refresh of the blog; it only takes a lock, so it never blocks a request
+func (d *RefreshDispatcher) Enqueue(blogID string) {
+	d.enqueued.Add(1)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.queued[blogID] {
+		d.coalesced.Add(1)
+		return
+	}
+	d.queued[blogID] = true
+	d.pending = append(d.pending, blogID)
+}
+
+// Stats returns the counters since the server started
+func (d *RefreshDispatcher) Stats() domain.DispatcherStats {
+	return domain.DispatcherStats{
+		Enqueued:  d.enqueued.Load(),
+		Coalesced: d.coalesced.Load(),
+		Processed: d.processed.Load(),
+	}
+}
+
+// HandleWith registers refresh as the handler of the batch jobs on the queue
+func (d *RefreshDispatcher) HandleWith(refresh func(ctx context.Context, blogIDs []string) error) {
+	d.queue.Handle(domain.JobRefreshPopularity, func(ctx context.Context, payload string) error {
+		blogIDs := strings.Split(payload, ",")
+		if err := refresh(ctx, blogIDs); err != nil {
+			return err
+		}
+		d.processed.Add(int64(len(blogIDs)))
+		return nil
+	})
+}
+
+// Start stores the blogs of each window as jobs until ctx is done, then stores what is left
+func (d *RefreshDispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(d.window)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				d.flush(context.Background())
+				log.Println("Blog refresh dispatcher shutting down...")
+				return
+			case <-ticker.C:
+				d.flush(ctx)
+			}
+		}
+	}()
+}
+
+func (d *RefreshDispatcher) flush(ctx context.Context) {
+	d.mu.Lock()
+	blogIDs := d.pending
+	d.pending = nil
+	d.queued = make(map[string]bool)
+	d.mu.Unlock()
+
+	if len(blogIDs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, refreshFlushTimeout)
+	defer cancel()
+
+	for start := 0; start < len(blogIDs); start += d.batchSize {
+		batch := blogIDs[start:min(start+d.batchSize, len(blogIDs))]
+		job := domain.Job{Type: domain.JobRefreshPopularity, Payload: strings.Join(batch, ",")}
+		if err := d.queue.Push(ctx, job); err != nil {
+			log.Println("Failed to enqueue refresh of blogs", batch, err)
+		}
+	}
+}
@@ -9,20 +9,27 @@ import (
 )
 
 
-func StartBlogRefreshWorker(ctx context.Context, uc domain.BlogUsecase) {
+// StartBlogPublishScheduler publishes scheduled blogs once their publish_at has passed.
+// Due blogs are looked up in MongoDB on every tick (and right away on boot),
+// so nothing scheduled is lost when the server restarts.
+func StartBlogPublishScheduler(ctx context.Context, uc domain.BlogUsecase, interval time.Duration) {
 	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
 		for {
+			published, err := uc.PublishDueBlogs(ctx)
+			if err != nil {
+				log.Println("Scheduled publishing failed:", err)
+			} else if published > 0 {
+				log.Println("Published scheduled blogs:", published)
+			}
+
 			select {
 			case <-ctx.Done():
-				log.Println("Blog refresh worker shutting down...")
+				log.Println("Blog publish scheduler shutting down...")
 				return
-			case blogID := <-BlogRefreshQueue:
-				log.Println("Refreshing blog:", blogID)
-				err := uc.RefreshPopularity(ctx, blogID)
-				if err != nil {
-					log.Println("Refresh failed:", err)
-				}
-				time.Sleep(100 * time.Millisecond) 
+			case <-ticker.C:
 			}
 		}
 	}()
@@ -1,17 +1,146 @@
 package infrastructure
 
-import domain "github.com/gedyzed/blog-starter-project/Domain"
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
 
-var BlogRefreshQueue = make(chan string, 1000)
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
 
-type BlogQueue struct {
+// jobPollInterval is how often idle workers look for due jobs that were not enqueued by this server, such as retries
+const jobPollInterval = time.Second
+
+// JobHandler runs one job; an error makes the job retry later
+type JobHandler func(ctx context.Context, payload string) error
+
+// JobQueuePolicy sets how many workers process jobs and how failed jobs are retried
+type JobQueuePolicy struct {
+	Workers     int
+	MaxAttempts int           // a job that fails this many times is moved to the dead letters
+	RetryBase   time.Duration // delay before the first retry, doubled for every further attempt
+	RetryMax    time.Duration // longest delay between two attempts
+	Lease       time.Duration // how long a job may run before another worker may pick it up
+}
+
+// JobQueue is a MongoDB-backed job queue: jobs are stored until a handler succeeds,
+// so they survive restarts and are delivered at least once.
+type JobQueue struct {
+	repo     domain.JobRepository
+	policy   JobQueuePolicy
+	handlers map[string]JobHandler
+	wake     chan struct{}
+}
+
+func NewJobQueue(repo domain.JobRepository, policy JobQueuePolicy) *JobQueue {
+	return &JobQueue{
+		repo:     repo,
+		policy:   policy,
+		handlers: make(map[string]JobHandler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Handle registers the handler of a job type; it must be called before Start
+func (q *JobQueue) Handle(jobType string, handler JobHandler) {
+	q.handlers[jobType] = handler
+}
+
+// Push stores a job and wakes an idle worker; it never waits for the job to run
+func (q *JobQueue) Push(ctx context.Context, job domain.Job) error {
+	if err := q.repo.CreateJob(ctx, job); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
 }
 
-func NewBlogQueue() domain.BlogRefreshDispatcher {
-	return &BlogQueue{}
+// Start runs the worker pool until ctx is done
+func (q *JobQueue) Start(ctx context.Context) {
+	for i := 0; i < q.policy.Workers; i++ {
+		go q.work(ctx)
+	}
 }
 
-func (b *BlogQueue) Enqueue(blogID string) {
-	BlogRefreshQueue <- blogID
+func (q *JobQueue) work(ctx context.Context) {
+	for {
+		job, err := q.repo.ClaimJob(ctx, time.Now(), q.policy.Lease)
+		if err != nil && ctx.Err() == nil {
+			log.Println("Failed to claim job:", err)
+		}
+		if job != nil {
+			q.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Job worker shutting down...")
+			return
+		case <-q.wake:
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+func (q *JobQueue) run(ctx context.Context, job *domain.Job) {
+	id := job.ID.Hex()
+	// a worker whose lease ran out cannot finish the job, as another worker may be running it by now
+	leaseID := job.LeaseID.Hex()
+
+	err := q.handle(ctx, job)
+	if ctx.Err() != nil {
+		// shutting down; the job is picked up again once its lease expires
+		return
+	}
+
+	switch {
+	case err == nil:
+		if err := q.repo.CompleteJob(ctx, id, leaseID); err != nil {
+			log.Println("Failed to complete job", id, err)
+		}
+	case job.Attempts >= q.policy.MaxAttempts:
+		log.Printf("Job %s (%s %s) failed for good: %v", id, job.Type, job.Payload, err)
+		if err := q.repo.BuryJob(ctx, id, leaseID, err.Error()); err != nil {
+			log.Println("Failed to move job to dead letters", id, err)
+		}
+	default:
+		log.Printf("Job %s (%s %s) failed, attempt %d: %v", id, job.Type, job.Payload, job.Attempts, err)
+		if err := q.repo.RetryJob(ctx, id, leaseID, time.Now().Add(q.backoff(job.Attempts)), err.Error()); err != nil {
+			log.Println("Failed to retry job", id, err)
+		}
+	}
 }
 
+// handle runs the job's handler within its lease, turning a panic into an error
+func (q *JobQueue) handle(ctx context.Context, job *domain.Job) (err error) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.policy.Lease)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job.Payload)
+}
+
+// backoff is the delay after the given failed attempt: RetryBase doubled per earlier attempt, up to RetryMax
+func (q *JobQueue) backoff(attempts int) time.Duration {
+	delay := q.policy.RetryBase
+	for i := 1; i < attempts && delay < q.policy.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, q.policy.RetryMax)
+}
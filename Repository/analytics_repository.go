@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// analyticsRepository stores one document of counters per blog and hour
+type analyticsRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAnalyticsRepository(coll *mongo.Collection) domain.AnalyticsRepository {
+	return &analyticsRepository{collection: coll}
+}
+
+// statSums sums every counter of the matched buckets in a $group stage
+var statSums = bson.M{
+	"views":        bson.M{"$sum": "$views"},
+	"unique_views": bson.M{"$sum": "$unique_views"},
+	"likes":        bson.M{"$sum": "$likes"},
+	"dislikes":     bson.M{"$sum": "$dislikes"},
+	"reactions":    bson.M{"$sum": "$reactions"},
+	"comments":     bson.M{"$sum": "$comments"},
+}
+
+// Record adds the delta to the counters of the hour the activity happened in
+func (r *analyticsRepository) Record(ctx context.Context, blogID string, authorID string, at time.Time, delta domain.StatCounters) error {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+	authorObjID, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return fmt.Errorf("invalid author ID: %w", err)
+	}
+
+	inc := bson.M{}
+	for field, value := range map[string]int{
+		"views":        delta.Views,
+		"unique_views": delta.UniqueViews,
+		"likes":        delta.Likes,
+		"dislikes":     delta.Dislikes,
+		"reactions":    delta.Reactions,
+		"comments":     delta.Comments,
+	} {
+		if value != 0 {
+			inc[field] = value
+		}
+	}
+	if len(inc) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"blog_id": blogObjID, "bucket": at.UTC().Truncate(time.Hour)}
+	update := bson.M{
+		"$inc":         inc,
+		"$setOnInsert": bson.M{"author_id": authorObjID},
+	}
+	if _, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to record analytics: %w", err)
+	}
+
+	return nil
+}
+
+// GetBlogSeries returns the hourly buckets of a blog in [from, to), oldest first
+func (r *analyticsRepository) GetBlogSeries(ctx context.Context, blogID string, from time.Time, to time.Time) ([]domain.StatBucket, error) {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	filter := bson.M{"blog_id": blogObjID, "bucket": bson.M{"$gte": from, "$lt": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "bucket", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching analytics: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []domain.StatBucket{}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, fmt.Errorf("failed decoding analytics: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// GetSeriesByBlogIDs returns the hourly buckets of each of the given blogs in [from, to), oldest first
+func (r *analyticsRepository) GetSeriesByBlogIDs(ctx context.Context, blogIDs []string, from time.Time, to time.Time) (map[string][]domain.StatBucket, error) {
+	blogObjIDs, err := parseBlogIDs(blogIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"blog_id": bson.M{"$in": blogObjIDs}, "bucket": bson.M{"$gte": from, "$lt": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "bucket", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching analytics: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var buckets []struct {
+		BlogID            primitive.ObjectID `bson:"blog_id"`
+		domain.StatBucket `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, fmt.Errorf("failed decoding analytics: %w", err)
+	}
+
+	series := make(map[string][]domain.StatBucket, len(blogIDs))
+	for _, b := range buckets {
+		blogID := b.BlogID.Hex()
+		series[blogID] = append(series[blogID], b.StatBucket)
+	}
+	return series, nil
+}
+
+// GetAuthorSeries returns the hourly buckets of an author summed over all their blogs, oldest first
+func (r *analyticsRepository) GetAuthorSeries(ctx context.Context, authorID string, from time.Time, to time.Time) ([]domain.StatBucket, error) {
+	authorObjID, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid author ID: %w", err)
+	}
+
+	group := bson.M{"_id": "$bucket"}
+	for field, sum := range statSums {
+		group[field] = sum
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"author_id": authorObjID, "bucket": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$addFields", Value: bson.M{"bucket": "$_id"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "bucket", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed aggregating analytics: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []domain.StatBucket{}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, fmt.Errorf("failed decoding analytics: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// GetAuthorTopBlogs sums the activity of each of an author's blogs in [from, to), most viewed first
+func (r *analyticsRepository) GetAuthorTopBlogs(ctx context.Context, authorID string, from time.Time, to time.Time, limit int) ([]domain.BlogStatSummary, error) {
+	authorObjID, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid author ID: %w", err)
+	}
+
+	group := bson.M{"_id": "$blog_id"}
+	for field, sum := range statSums {
+		group[field] = sum
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"author_id": authorObjID, "bucket": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$sort", Value: bson.D{{Key: "views", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: int64(limit)}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed aggregating analytics: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	summaries := []domain.BlogStatSummary{}
+	if err := cursor.All(ctx, &summaries); err != nil {
+		return nil, fmt.Errorf("failed decoding analytics: %w", err)
+	}
+
+	return summaries, nil
+}
+
+func (r *analyticsRepository) DeleteByBlogID(ctx context.Context, blogID string) error {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"blog_id": blogObjID}); err != nil {
+		return fmt.Errorf("failed to delete analytics: %w", err)
+	}
+	return nil
+}
+
+func (r *analyticsRepository) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "bucket", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "bucket", Value: 1}}, // for the author dashboard
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	domain "github.com/gedyzed/blog-starter-project/Domain"
 	"go.mongodb.org/mongo-driver/bson"
@@ -18,25 +21,34 @@ type blogRepository struct {
 	collection     *mongo.Collection
 	userRepository domain.IUserRepository
 	blogCache      domain.Cache[*domain.Blog]
+	slugCache      domain.Cache[string] // ID of the blog every looked up slug, current or previous, belongs to
 	sortedCache    domain.SortedCache[[]domain.Blog]
+	feedCache      domain.SortedCache[*domain.Feed]
+	sitemapCache   domain.SortedCache[[]domain.SitemapURL]
+	bookmarkRepo   domain.BookmarkRepository
 }
 
-func NewBlogRepository(coll *mongo.Collection, userRepository domain.IUserRepository, blogCache domain.Cache[*domain.Blog], sorted domain.SortedCache[[]domain.Blog]) domain.BlogRepository {
+func NewBlogRepository(coll *mongo.Collection, userRepository domain.IUserRepository, blogCache domain.Cache[*domain.Blog], slugCache domain.Cache[string], sorted domain.SortedCache[[]domain.Blog], feedCache domain.SortedCache[*domain.Feed], sitemapCache domain.SortedCache[[]domain.SitemapURL], bookmarkRepo domain.BookmarkRepository) domain.BlogRepository {
 	return &blogRepository{
 		collection:     coll,
 		userRepository: userRepository,
 		blogCache:      blogCache,
+		slugCache:      slugCache,
 		sortedCache:    sorted,
+		feedCache:      feedCache,
+		sitemapCache:   sitemapCache,
+		bookmarkRepo:   bookmarkRepo,
 	}
 }
 
-func (r *blogRepository) GetAllBlogs(ctx context.Context, page int, limit int, sort string) ([]domain.Blog, int, error) {
+func (r *blogRepository) GetAllBlogs(ctx context.Context, page int, limit int, sort string, status string) ([]domain.Blog, int, error) {
 	sortKey := sort
 	if sortKey == "" {
 		sortKey = "latest"
 	}
 
-	cacheKey := fmt.Sprintf("blogs:%s:%d:%d", sortKey, page, limit)
+	// the status is part of the key so a cached page of one status is never served for another
+	cacheKey := fmt.Sprintf("blogs:%s:%s:%d:%d", status, sortKey, page, limit)
 
 	if cachedBlogs, found := r.sortedCache.Get(cacheKey); found {
 		log.Println("Cache HIT for blogs for:", cacheKey)
@@ -50,13 +62,17 @@ func (r *blogRepository) GetAllBlogs(ctx context.Context, page int, limit int, s
 	switch sort {
 	case "popular":
 		findOptions.SetSort(bson.D{{Key: "popularity_score", Value: -1}})
+	case "trending":
+		findOptions.SetSort(bson.D{{Key: "trending_score", Value: -1}, {Key: "created", Value: -1}})
 	case "oldest":
 		findOptions.SetSort(bson.D{{Key: "created", Value: 1}})
 	default:
 		findOptions.SetSort(bson.D{{Key: "created", Value: -1}})
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	filter := statusFilter(status)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to fetch blogs: %w", err)
 	}
@@ -66,7 +82,7 @@ func (r *blogRepository) GetAllBlogs(ctx context.Context, page int, limit int, s
 		return nil, 0, fmt.Errorf("failed to decode blogs: %w", err)
 	}
 
-	totalCount, err := r.collection.CountDocuments(ctx, bson.M{})
+	totalCount, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count blogs: %w", err)
 	}
@@ -76,6 +92,144 @@ func (r *blogRepository) GetAllBlogs(ctx context.Context, page int, limit int, s
 	return blogs, int(totalCount), nil
 }
 
+func (r *blogRepository) GetBlogsByAuthor(ctx context.Context, userID string, status string, page int, limit int) ([]domain.Blog, int, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"user_id": userObjID}
+	if status != "" {
+		filter = statusFilter(status)
+		filter["user_id"] = userObjID
+		delete(filter, "hidden") // authors still see their own hidden blogs
+	}
+
+	skip := int64((page - 1) * limit)
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "updated", Value: -1}})
+
+	var blogs []domain.Blog
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &blogs); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding blogs: %w", err)
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting blogs: %w", err)
+	}
+
+	return blogs, int(total), nil
+}
+
+// GetBlogsByAuthors returns the published blogs of the given authors, newest first
+func (r *blogRepository) GetBlogsByAuthors(ctx context.Context, authorIDs []string, page int, limit int) ([]domain.Blog, int, error) {
+	authorObjIDs := make([]primitive.ObjectID, 0, len(authorIDs))
+	for _, id := range authorIDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid user ID: %w", err)
+		}
+		authorObjIDs = append(authorObjIDs, objID)
+	}
+
+	filter := statusFilter(domain.BlogStatusPublished)
+	filter["user_id"] = bson.M{"$in": authorObjIDs}
+
+	skip := int64((page - 1) * limit)
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created", Value: -1}})
+
+	var blogs []domain.Blog
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &blogs); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding blogs: %w", err)
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting blogs: %w", err)
+	}
+
+	return blogs, int(total), nil
+}
+
+// GetBlogsByIDs returns the published blogs among the given IDs, in no particular order
+func (r *blogRepository) GetBlogsByIDs(ctx context.Context, ids []string) ([]domain.Blog, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blog ID: %w", err)
+		}
+		objIDs = append(objIDs, objID)
+	}
+
+	filter := statusFilter(domain.BlogStatusPublished)
+	filter["_id"] = bson.M{"$in": objIDs}
+
+	var blogs []domain.Blog
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &blogs); err != nil {
+		return nil, fmt.Errorf("failed decoding blogs: %w", err)
+	}
+
+	return blogs, nil
+}
+
+// GetBlogsAfter pages through every blog, whatever its status, in ID order; an empty afterID starts at the first blog
+func (r *blogRepository) GetBlogsAfter(ctx context.Context, afterID string, limit int) ([]domain.Blog, error) {
+	filter := bson.M{}
+	if afterID != "" {
+		objID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blog ID: %w", err)
+		}
+		filter["_id"] = bson.M{"$gt": objID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	var blogs []domain.Blog
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &blogs); err != nil {
+		return nil, fmt.Errorf("failed decoding blogs: %w", err)
+	}
+
+	return blogs, nil
+}
+
 func (r *blogRepository) GetBlogByID(ctx context.Context, id string) (*domain.Blog, error) {
 	if blog, found := r.blogCache.Get(id); found {
 		log.Println("cache hit for getting blog by ID")
@@ -97,19 +251,57 @@ func (r *blogRepository) GetBlogByID(ctx context.Context, id string) (*domain.Bl
 	return &blog, nil
 }
 
-func (r *blogRepository) IncrementBlogViews(ctx context.Context, id string) error {
+func (r *blogRepository) GetBlogBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	// the cached ID is checked against the blog, so a mapping another server made stale is never served
+	if id, found := r.slugCache.Get(slug); found {
+		if blog, err := r.GetBlogByID(ctx, id); err == nil && hasSlug(blog, slug) {
+			return blog, nil
+		}
+		r.slugCache.Delete(slug)
+	}
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"slug": slug},
+			bson.M{"previous_slugs": slug},
+		},
+	}
+
+	var blog domain.Blog
+	err := r.collection.FindOne(ctx, filter).Decode(&blog)
+	if err != nil {
+		return nil, fmt.Errorf("blog not found: %w", err)
+	}
+
+	r.slugCache.Set(slug, blog.ID.Hex())
+	r.blogCache.Set(blog.ID.Hex(), &blog)
+	return &blog, nil
+}
+
+// IncrementBlogViews counts a view; unique views also raise the unique view count.
+// Blogs without a unique view count yet start from their raw view count.
+func (r *blogRepository) IncrementBlogViews(ctx context.Context, id string, unique bool) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid blog id: %w", err)
 	}
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$inc": bson.M{"view_count": 1}})
+
+	set := bson.M{"view_count": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$view_count", 0}}, 1}}}
+	if unique {
+		set["unique_view_count"] = bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$unique_view_count", bson.M{"$ifNull": bson.A{"$view_count", 0}}}}, 1}}
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, mongo.Pipeline{{{Key: "$set", Value: set}}})
 
 	if cachedBlog, found := r.blogCache.Get(id); found {
+		if unique {
+			cachedBlog.UniqueViewCount = cachedBlog.UniqueViews() + 1
+		}
 		cachedBlog.ViewCount++
 		r.blogCache.Set(id, cachedBlog)
 	}
 
 	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
 
 	return err
 }
@@ -128,14 +320,35 @@ func (r *blogRepository) CreateBlog(ctx context.Context, blog domain.Blog, userI
 	blog.AuthorName = fmt.Sprintf("%s %s", user.Firstname, user.Lastname)
 
 	blog.ID = primitive.NewObjectID()
+	blog.Slug, err = r.uniqueSlug(ctx, blog.Title, blog.ID)
+	if err != nil {
+		return nil, err
+	}
+	blog.PreviousSlugs = nil
 	blog.UserID = userObjID
 	blog.Created = time.Now()
 	blog.Updated = blog.Created
 	blog.ViewCount = 0
-	blog.LikedUsers = []string{}
-	blog.DislikedUsers = []string{}
+	blog.UniqueViewCount = 0
 	blog.Likes = 0
 	blog.Dislikes = 0
+	blog.Reactions = nil
+	// counts, scores and moderation state are only ever set by the server, never by the request
+	blog.CommentsCount = 0
+	blog.PopularityScore = 0
+	blog.TrendingScore = 0
+	blog.Hidden = false
+	blog.PublishedAt = nil
+	if blog.Status == "" {
+		blog.Status = domain.BlogStatusDraft
+	}
+	if blog.Status == domain.BlogStatusPublished {
+		publishedAt := blog.Created
+		blog.PublishedAt = &publishedAt
+	}
+	if blog.Status != domain.BlogStatusScheduled {
+		blog.PublishAt = nil
+	}
 
 	_, err = r.collection.InsertOne(ctx, blog)
 	if err != nil {
@@ -145,228 +358,308 @@ func (r *blogRepository) CreateBlog(ctx context.Context, blog domain.Blog, userI
 	r.blogCache.Set(blog.ID.Hex(), &blog)
 	r.sortedCache.Invalidate("latest")
 	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
+	r.invalidatePublishedContent()
 
 	return &blog, nil
 }
 
-func (r *blogRepository) UpdateBlog(ctx context.Context, id string, userID string, input domain.BlogUpdateInput) error {
+func (r *blogRepository) UpdateBlog(ctx context.Context, id string, userID string, input domain.BlogUpdateInput, lastUpdated time.Time) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"title":   input.Title,
-			"content": input.Content,
-			"tags":    input.Tags,
-			"updated": time.Now(),
-		},
+	// only the fields in the request are changed, the others keep their values
+	fields := bson.M{"updated": time.Now()}
+	if input.Title != "" {
+		fields["title"] = input.Title
+	}
+	if input.Content != "" {
+		fields["content"] = input.Content
+		fields["content_html"] = input.ContentHTML
+	}
+	if input.Tags != nil {
+		fields["tags"] = input.Tags
+	}
+	if input.ModerateComments != nil {
+		fields["moderate_comments"] = *input.ModerateComments
 	}
 
-	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	var current domain.Blog
+	err = r.collection.FindOne(
+		ctx,
+		bson.M{"_id": objID},
+		options.FindOne().SetProjection(bson.M{"title": 1, "slug": 1, "previous_slugs": 1}),
+	).Decode(&current)
+	if err != nil {
+		return errors.New("blog not found")
+	}
+
+	// a new title gets a new slug; the old one is kept so existing links keep working
+	var renamed []string
+	if input.Title != "" && (input.Title != current.Title || current.Slug == "") {
+		slug, err := r.uniqueSlug(ctx, input.Title, objID)
+		if err != nil {
+			return err
+		}
+		if slug != current.Slug {
+			previous := make([]string, 0, len(current.PreviousSlugs)+1)
+			for _, s := range current.PreviousSlugs {
+				if s != slug {
+					previous = append(previous, s)
+				}
+			}
+			if current.Slug != "" {
+				previous = append(previous, current.Slug)
+			}
+			fields["slug"] = slug
+			fields["previous_slugs"] = previous
+			renamed = append(previous, slug)
+		}
+	}
+
+	// the edit is based on the version read at lastUpdated, so it is refused when another edit came in between
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID, "updated": lastUpdated}, bson.M{"$set": fields})
 	if err != nil {
 		return err
 	}
 	if res.MatchedCount == 0 {
-		return errors.New("blog not found")
+		r.blogCache.Delete(id)
+		return domain.ErrRevisionConflict
 	}
 
 	r.blogCache.Delete(id)
+	r.forgetSlugs(renamed)
 	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
 	r.sortedCache.Invalidate("latest")
 	r.sortedCache.Invalidate("oldest")
+	r.invalidatePublishedContent()
 
 	return nil
 }
 
-func (r *blogRepository) DeleteBlog(ctx context.Context, id string) error {
+func (r *blogRepository) UpdateBlogStatus(ctx context.Context, id string, status string, publishAt *time.Time) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	now := time.Now()
+	fields := bson.M{
+		"status":  status,
+		"updated": now,
+	}
+	if status == domain.BlogStatusPublished {
+		// a blog keeps the time it was first published when it is republished after being archived
+		fields["published_at"] = bson.M{"$ifNull": bson.A{"$published_at", now}}
+	}
+
+	update := mongo.Pipeline{{{Key: "$set", Value: fields}}}
+	if status == domain.BlogStatusScheduled && publishAt != nil {
+		fields["publish_at"] = *publishAt
+	} else {
+		update = append(update, bson.D{{Key: "$unset", Value: "publish_at"}})
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
 	if err != nil {
 		return err
 	}
-	if res.DeletedCount == 0 {
-		return errors.New("no blog found")
+	if res.MatchedCount == 0 {
+		return errors.New("blog not found")
 	}
 
 	r.blogCache.Delete(id)
 	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
 	r.sortedCache.Invalidate("latest")
 	r.sortedCache.Invalidate("oldest")
+	r.invalidatePublishedContent()
 
 	return nil
 }
 
-func (r *blogRepository) LikeBlog(ctx context.Context, id string, userID string) error {
-	blogID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
+func (r *blogRepository) PublishDueBlogs(ctx context.Context, now time.Time) ([]string, error) {
+	filter := bson.M{
+		"status":     domain.BlogStatusScheduled,
+		"publish_at": bson.M{"$lte": now},
 	}
 
-	filter := bson.M{"_id": blogID, "liked_users": userID}
-	exists := r.collection.FindOne(ctx, filter)
-	if exists.Err() == nil {
-		_, err := r.collection.UpdateOne(
-			ctx,
-			bson.M{"_id": blogID},
-			bson.M{
-				"$pull": bson.M{"liked_users": userID},
-				"$inc":  bson.M{"likes": -1},
-			},
-		)
-		if err == nil {
-			if cachedBlog, ok := r.blogCache.Get(id); ok && cachedBlog != nil {
-				if cachedBlog.Likes > 0 {
-					cachedBlog.Likes--
-				}
-				for i, Id := range cachedBlog.LikedUsers {
-					if Id == userID {
-						cachedBlog.LikedUsers = append(cachedBlog.LikedUsers[:i], cachedBlog.LikedUsers[i+1:]...)
-						break
-					}
-				}
-				r.blogCache.Set(id, cachedBlog)
-			}
-		}
-		r.sortedCache.Invalidate("popular")
-		return err
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch scheduled blogs: %w", err)
 	}
+	defer cursor.Close(ctx)
 
-	filter = bson.M{"_id": blogID, "disliked_users": userID}
-	exists = r.collection.FindOne(ctx, filter)
-	if exists.Err() == nil {
-		_, err := r.collection.UpdateOne(
-			ctx,
-			bson.M{"_id": blogID},
-			bson.M{
-				"$pull": bson.M{"disliked_users": userID},
-				"$inc":  bson.M{"dislikes": -1},
-			},
-		)
-		if err != nil {
-			if cachedBlog, ok := r.blogCache.Get(id); ok && cachedBlog != nil {
-				if cachedBlog.Dislikes > 0 {
-					cachedBlog.Dislikes--
-				}
+	var due []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &due); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduled blogs: %w", err)
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
 
-				for i, uid := range cachedBlog.DislikedUsers {
-					if uid == userID {
-						cachedBlog.DislikedUsers = append(cachedBlog.DislikedUsers[:i], cachedBlog.DislikedUsers[i+1:]...)
-						break
-					}
-				}
-				r.blogCache.Set(id, cachedBlog)
-			}
-			r.sortedCache.Invalidate("popular")
-			return err
-		}
+	objIDs := make([]primitive.ObjectID, 0, len(due))
+	for _, d := range due {
+		objIDs = append(objIDs, d.ID)
 	}
 
-	_, err = r.collection.UpdateOne(
+	// the status is re-checked so a blog unscheduled in the meantime is left alone
+	_, err = r.collection.UpdateMany(
 		ctx,
-		bson.M{"_id": blogID},
-		bson.M{
-			"$addToSet": bson.M{"liked_users": userID},
-			"$inc":      bson.M{"likes": 1},
+		bson.M{"_id": bson.M{"$in": objIDs}, "status": domain.BlogStatusScheduled},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"status":       domain.BlogStatusPublished,
+				"published_at": bson.M{"$ifNull": bson.A{"$published_at", "$publish_at"}},
+				"updated":      now,
+			}}},
+			{{Key: "$unset", Value: "publish_at"}},
 		},
 	)
-
-	if cachedBlog, ok := r.blogCache.Get(id); ok && cachedBlog != nil {
-		cachedBlog.Likes++
-		cachedBlog.LikedUsers = append(cachedBlog.LikedUsers, userID)
-		r.blogCache.Set(id, cachedBlog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish scheduled blogs: %w", err)
 	}
 
+	ids := make([]string, 0, len(objIDs))
+	for _, objID := range objIDs {
+		ids = append(ids, objID.Hex())
+		r.blogCache.Delete(objID.Hex())
+	}
 	r.sortedCache.Invalidate("popular")
-	return err
+	r.sortedCache.Invalidate("trending")
+	r.sortedCache.Invalidate("latest")
+	r.sortedCache.Invalidate("oldest")
+	r.invalidatePublishedContent()
+
+	return ids, nil
 }
 
-func (r *blogRepository) DislikeBlog(ctx context.Context, id string, userID string) error {
-	blogID, err := primitive.ObjectIDFromHex(id)
+func (r *blogRepository) DeleteBlog(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": blogID, "disliked_users": userID}
-	exists := r.collection.FindOne(ctx, filter)
-	if exists.Err() == nil {
-		_, err := r.collection.UpdateOne(
-			ctx,
-			bson.M{"_id": blogID},
-			bson.M{
-				"$pull": bson.M{"disliked_users": userID},
-				"$inc":  bson.M{"dislikes": -1},
-			},
-		)
-		if err == nil {
-			if cachedBlog, ok := r.blogCache.Get(id); ok && cachedBlog != nil {
-				if cachedBlog.Dislikes > 0 {
-					cachedBlog.Dislikes--
-				}
-				for i, Id := range cachedBlog.DislikedUsers {
-					if Id == userID {
-						cachedBlog.DislikedUsers = append(cachedBlog.DislikedUsers[:i], cachedBlog.DislikedUsers[i+1:]...)
-						break
-					}
-				}
-				r.blogCache.Set(id, cachedBlog)
-			}
-		}
-		r.sortedCache.Invalidate("popular")
+	var deleted domain.Blog
+	err = r.collection.FindOneAndDelete(
+		ctx,
+		bson.M{"_id": objID},
+		options.FindOneAndDelete().SetProjection(bson.M{"slug": 1, "previous_slugs": 1}),
+	).Decode(&deleted)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return errors.New("no blog found")
+	}
+	if err != nil {
 		return err
 	}
 
-	filter = bson.M{"_id": blogID, "liked_users": userID}
-	exists = r.collection.FindOne(ctx, filter)
-	if exists.Err() == nil {
-		_, err := r.collection.UpdateOne(
-			ctx,
-			bson.M{"_id": blogID},
-			bson.M{
-				"$pull": bson.M{"liked_users": userID},
-				"$inc":  bson.M{"likes": -1},
-			},
-		)
-		if err != nil {
-			if cachedBlog, ok := r.blogCache.Get(id); ok && cachedBlog != nil {
-				if cachedBlog.Likes > 0 {
-					cachedBlog.Likes--
-				}
-				for i, id := range cachedBlog.LikedUsers {
-					if id == userID {
-						cachedBlog.LikedUsers = append(cachedBlog.LikedUsers[:i], cachedBlog.LikedUsers[i+1:]...)
-						break
-					}
-				}
-				r.blogCache.Set(id, cachedBlog)
-			}
-		}
-		r.sortedCache.Invalidate("popular")
+	// the slugs are free to be taken by other blogs now
+	r.forgetSlugs(append(deleted.PreviousSlugs, deleted.Slug))
+
+	// bookmarks of a deleted blog would point at nothing
+	if err := r.bookmarkRepo.DeleteBookmarksByBlogID(ctx, id); err != nil {
+		log.Printf("failed to delete bookmarks of blog %s: %v", id, err)
+	}
+
+	r.blogCache.Delete(id)
+	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
+	r.sortedCache.Invalidate("latest")
+	r.sortedCache.Invalidate("oldest")
+	r.invalidatePublishedContent()
+
+	return nil
+}
+
+// SetBlogHidden hides a blog from every public listing, or makes it visible again
+func (r *blogRepository) SetBlogHidden(ctx context.Context, id string, hidden bool) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
 		return err
 	}
 
-	_, err = r.collection.UpdateOne(
-		ctx,
-		bson.M{"_id": blogID},
-		bson.M{
-			"$addToSet": bson.M{"disliked_users": userID},
-			"$inc":      bson.M{"dislikes": 1},
-		},
-	)
+	update := bson.M{"$unset": bson.M{"hidden": ""}}
+	if hidden {
+		update = bson.M{"$set": bson.M{"hidden": true}}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("blog not found")
+	}
+
+	r.blogCache.Delete(id)
+	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
+	r.sortedCache.Invalidate("latest")
+	r.sortedCache.Invalidate("oldest")
+	r.invalidatePublishedContent()
+
+	return nil
+}
+
+// IncrementVotes adjusts a blog's like and dislike counts
+func (r *blogRepository) IncrementVotes(ctx context.Context, id string, likes int, dislikes int) error {
+	if likes == 0 && dislikes == 0 {
+		return nil
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid blog id: %w", err)
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$inc": bson.M{"likes": likes, "dislikes": dislikes}})
+	if err != nil {
+		return fmt.Errorf("failed to update votes: %w", err)
+	}
+
+	if cachedBlog, found := r.blogCache.Get(id); found && cachedBlog != nil {
+		cachedBlog.Likes += likes
+		cachedBlog.Dislikes += dislikes
+		r.blogCache.Set(id, cachedBlog)
+	}
+
+	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
+
+	return nil
+}
+
+// IncrementReaction adjusts the stored count of one reaction type on a blog
+func (r *blogRepository) IncrementReaction(ctx context.Context, id string, reactionType string, delta int) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid blog id: %w", err)
+	}
 
-	if cachedBlog, ok := r.blogCache.Get(id); ok && cachedBlog != nil {
-		cachedBlog.Dislikes++
-		cachedBlog.DislikedUsers = append(cachedBlog.DislikedUsers, userID)
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$inc": bson.M{"reactions." + reactionType: delta}})
+	if err != nil {
+		return fmt.Errorf("failed to update reactions: %w", err)
+	}
+
+	if cachedBlog, found := r.blogCache.Get(id); found && cachedBlog != nil {
+		reactions := make(map[string]int, len(cachedBlog.Reactions)+1)
+		for t, count := range cachedBlog.Reactions {
+			reactions[t] = count
+		}
+		reactions[reactionType] += delta
+		cachedBlog.Reactions = reactions
 		r.blogCache.Set(id, cachedBlog)
 	}
+
 	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
 
-	return err
+	return nil
 }
 
 func (r *blogRepository) EnsureIndexes(ctx context.Context) error {
@@ -386,44 +679,176 @@ func (r *blogRepository) EnsureIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "popularity_score", Value: -1}},
 		},
+		{
+			Keys: bson.D{{Key: "trending_score", Value: -1}, {Key: "created", Value: -1}},
+		},
 		{
 			Keys: bson.D{{Key: "tags", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created", Value: -1}}, // for the followed authors feed
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "publish_at", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "previous_slugs", Value: 1}},
+		},
 	}
 	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
 	return err
 }
 
-func (r *blogRepository) UpdateStats(ctx context.Context, blogID string, score float64, commentCount int) error {
-	objID, err := primitive.ObjectIDFromHex(blogID)
-	if err != nil {
-		return fmt.Errorf("invalid blog id: %w", err)
+// UpdateStats saves the refreshed statistics of many blogs in one round trip
+func (r *blogRepository) UpdateStats(ctx context.Context, stats []domain.BlogStats) error {
+	if len(stats) == 0 {
+		return nil
 	}
-	update := bson.M{
-		"$set": bson.M{
-			"popularity_score": score,
-			"comment_count":    commentCount,
-		},
+
+	models := make([]mongo.WriteModel, 0, len(stats))
+	for _, s := range stats {
+		objID, err := primitive.ObjectIDFromHex(s.BlogID)
+		if err != nil {
+			return fmt.Errorf("invalid blog id: %w", err)
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": objID}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"popularity_score": s.PopularityScore,
+					"trending_score":   s.TrendingScore,
+					"comments_count":   s.CommentCount,
+				},
+				// earlier versions wrote the count to a misspelled field
+				"$unset": bson.M{"comment_count": ""},
+			}))
 	}
-	_, err = r.collection.UpdateByID(ctx, objID, update)
-	if err != nil {
+
+	if _, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
 		return fmt.Errorf("failed to update popularity statistics: %w", err)
 	}
 
-	if cachedBlog, ok := r.blogCache.Get(blogID); ok && cachedBlog != nil {
-		cachedBlog.PopularityScore = score
-		cachedBlog.CommentsCount = commentCount
-		r.blogCache.Set(blogID, cachedBlog)
+	for _, s := range stats {
+		if cachedBlog, ok := r.blogCache.Get(s.BlogID); ok && cachedBlog != nil {
+			cachedBlog.PopularityScore = s.PopularityScore
+			cachedBlog.TrendingScore = s.TrendingScore
+			cachedBlog.CommentsCount = s.CommentCount
+			r.blogCache.Set(s.BlogID, cachedBlog)
+		}
 	}
 
 	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
 
 	return nil
+}
+
+// UpdatePopularityScores sets the popularity score of many blogs in one round trip
+func (r *blogRepository) UpdatePopularityScores(ctx context.Context, scores map[string]float64) error {
+	if len(scores) == 0 {
+		return nil
+	}
 
+	models := make([]mongo.WriteModel, 0, len(scores))
+	for id, score := range scores {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return fmt.Errorf("invalid blog id: %w", err)
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": objID}).
+			SetUpdate(bson.M{"$set": bson.M{"popularity_score": score}}))
+	}
+
+	if _, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to update popularity scores: %w", err)
+	}
+
+	for id, score := range scores {
+		if cachedBlog, ok := r.blogCache.Get(id); ok && cachedBlog != nil {
+			cachedBlog.PopularityScore = score
+			r.blogCache.Set(id, cachedBlog)
+		}
+	}
+
+	r.sortedCache.Invalidate("popular")
+	r.sortedCache.Invalidate("trending")
+
+	return nil
 }
 
-func (r *blogRepository) FilterBlogs(ctx context.Context, startDate, endDate *time.Time, tags []string, sort string, page, limit int) ([]domain.Blog, int, error) {
-	filter := bson.M{}
+func (r *blogRepository) GetSitemapBlogs(ctx context.Context) ([]domain.Blog, error) {
+	findOptions := options.Find().
+		SetProjection(bson.M{"_id": 1, "slug": 1, "updated": 1}).
+		SetSort(bson.D{{Key: "updated", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, statusFilter(domain.BlogStatusPublished), findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var blogs []domain.Blog
+	if err := cursor.All(ctx, &blogs); err != nil {
+		return nil, fmt.Errorf("failed decoding blogs: %w", err)
+	}
+
+	return blogs, nil
+}
+
+func (r *blogRepository) GetAuthorsLastUpdated(ctx context.Context) ([]domain.LastUpdated, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: statusFilter(domain.BlogStatusPublished)}},
+		{{Key: "$group", Value: bson.M{"_id": "$user_id", "updated": bson.M{"$max": "$updated"}}}},
+		{{Key: "$project", Value: bson.M{"key": bson.M{"$toString": "$_id"}, "updated": 1}}},
+	}
+	return r.aggregateLastUpdated(ctx, pipeline)
+}
+
+func (r *blogRepository) GetTagsLastUpdated(ctx context.Context) ([]domain.LastUpdated, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: statusFilter(domain.BlogStatusPublished)}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "updated": bson.M{"$max": "$updated"}}}},
+		{{Key: "$project", Value: bson.M{"key": "$_id", "updated": 1}}},
+	}
+	return r.aggregateLastUpdated(ctx, pipeline)
+}
+
+func (r *blogRepository) aggregateLastUpdated(ctx context.Context, pipeline mongo.Pipeline) ([]domain.LastUpdated, error) {
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed aggregating blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []domain.LastUpdated
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("failed decoding aggregation: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *blogRepository) FilterBlogs(ctx context.Context, authorID string, startDate, endDate *time.Time, tags []string, sort string, page, limit int) ([]domain.Blog, int, error) {
+	filter := statusFilter(domain.BlogStatusPublished)
+	if authorID != "" {
+		authorObjID, err := primitive.ObjectIDFromHex(authorID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid author ID: %w", err)
+		}
+		filter["user_id"] = authorObjID
+	}
 	if len(tags) > 0 {
 		filter["tags"] = bson.M{"$in": tags}
 	}
@@ -439,6 +864,10 @@ func (r *blogRepository) FilterBlogs(ctx context.Context, startDate, endDate *ti
 	}
 
 	skip := int64((page - 1) * limit)
+	if sort == "published" {
+		return r.filterByPublished(ctx, filter, skip, int64(limit))
+	}
+
 	findOptions := options.Find()
 	findOptions.SetSkip(skip)
 	findOptions.SetLimit(int64(limit))
@@ -446,6 +875,8 @@ func (r *blogRepository) FilterBlogs(ctx context.Context, startDate, endDate *ti
 	switch sort {
 	case "popular":
 		findOptions.SetSort(bson.D{{Key: "popularity_score", Value: -1}})
+	case "trending":
+		findOptions.SetSort(bson.D{{Key: "trending_score", Value: -1}, {Key: "created", Value: -1}})
 	case "oldest":
 		findOptions.SetSort(bson.D{{Key: "created", Value: 1}})
 	default:
@@ -473,14 +904,43 @@ func (r *blogRepository) FilterBlogs(ctx context.Context, startDate, endDate *ti
 	return blogs, int(total), nil
 }
 
+// filterByPublished lists the matching blogs most recently published first.
+// Blogs published before published_at was recorded are ordered by their creation time instead.
+func (r *blogRepository) filterByPublished(ctx context.Context, filter bson.M, skip, limit int64) ([]domain.Blog, int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"published_sort": bson.M{"$ifNull": bson.A{"$published_at", "$created"}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "published_sort", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$skip", Value: skip}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"published_sort": 0}}},
+	}
+
+	var blogs []domain.Blog
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &blogs); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding blogs: %w", err)
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting blogs: %w", err)
+	}
+
+	return blogs, int(total), nil
+}
+
 func (r *blogRepository) SearchBlogs(ctx context.Context, query string, limit, page int) ([]domain.Blog, int, error) {
 	skip := (page - 1) * limit
 
-	filter := bson.M{
-		"$text": bson.M{
-			"$search": query,
-		},
-	}
+	filter := statusFilter(domain.BlogStatusPublished)
+	filter["$text"] = bson.M{"$search": query}
 
 	findOptions := options.Find()
 	findOptions.SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}})
@@ -507,3 +967,88 @@ func (r *blogRepository) SearchBlogs(ctx context.Context, query string, limit, p
 
 	return blogs, int(total), nil
 }
+
+// statusFilter matches blogs in the given lifecycle status.
+// Blogs created before statuses existed have no status field and are treated as published;
+// published blogs hidden after abuse reports are left out.
+func statusFilter(status string) bson.M {
+	if status == domain.BlogStatusPublished {
+		return bson.M{
+			"status": bson.M{"$in": bson.A{domain.BlogStatusPublished, nil}},
+			"hidden": bson.M{"$ne": true},
+		}
+	}
+	return bson.M{"status": status}
+}
+
+// invalidatePublishedContent drops the feeds and sitemap built from published blogs,
+// so they are rebuilt on their next request
+func (r *blogRepository) invalidatePublishedContent() {
+	r.feedCache.Invalidate(domain.FeedCacheGroup)
+	r.sitemapCache.Invalidate(domain.SitemapCacheGroup)
+}
+
+const maxSlugLength = 80
+
+// hasSlug reports whether the slug is the blog's current slug or one it had before
+func hasSlug(blog *domain.Blog, slug string) bool {
+	return blog.Slug == slug || slices.Contains(blog.PreviousSlugs, slug)
+}
+
+// forgetSlugs drops cached slug lookups, so the next lookup of each reads the blog it belongs to now
+func (r *blogRepository) forgetSlugs(slugs []string) {
+	for _, slug := range slugs {
+		if slug != "" {
+			r.slugCache.Delete(slug)
+		}
+	}
+}
+
+// slugify turns a title into a lowercase, dash separated URL segment
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if runes := []rune(slug); len(runes) > maxSlugLength {
+		slug = strings.TrimSuffix(string(runes[:maxSlugLength]), "-")
+	}
+	if slug == "" {
+		slug = "post"
+	}
+	return slug
+}
+
+// uniqueSlug returns the slug of the title, suffixed with -2, -3... when it is already
+// used (currently or previously) by another blog than the one with the given id
+func (r *blogRepository) uniqueSlug(ctx context.Context, title string, id primitive.ObjectID) (string, error) {
+	base := slugify(title)
+	slug := base
+
+	for n := 2; ; n++ {
+		filter := bson.M{
+			"_id": bson.M{"$ne": id},
+			"$or": bson.A{
+				bson.M{"slug": slug},
+				bson.M{"previous_slugs": slug},
+			},
+		}
+		count, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			return "", fmt.Errorf("failed to check slug: %w", err)
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
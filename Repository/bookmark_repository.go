@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type bookmarkRepository struct {
+	bookmarks    *mongo.Collection
+	readingLists *mongo.Collection
+}
+
+func NewBookmarkRepository(bookmarkCollection, readingListCollection *mongo.Collection) domain.BookmarkRepository {
+	return &bookmarkRepository{
+		bookmarks:    bookmarkCollection,
+		readingLists: readingListCollection,
+	}
+}
+
+func (r *bookmarkRepository) CreateBookmark(ctx context.Context, bookmark domain.Bookmark) (*domain.Bookmark, error) {
+	bookmark.ID = primitive.NewObjectID()
+
+	if _, err := r.bookmarks.InsertOne(ctx, bookmark); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("blog already bookmarked")
+		}
+		return nil, fmt.Errorf("failed to insert bookmark: %w", err)
+	}
+
+	return &bookmark, nil
+}
+
+// GetBookmarks lists a user's bookmarks. An empty listID lists every bookmark, newest first;
+// bookmarks of a reading list are returned in the list's order.
+func (r *bookmarkRepository) GetBookmarks(ctx context.Context, userID string, listID string, unreadOnly bool, page int, limit int) ([]domain.Bookmark, int, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"user_id": userObjID}
+	sort := bson.D{{Key: "created", Value: -1}}
+	if listID != "" {
+		listObjID, err := primitive.ObjectIDFromHex(listID)
+		if err != nil {
+			return nil, 0, errors.New("reading list not found")
+		}
+		filter["list_id"] = listObjID
+		sort = bson.D{{Key: "position", Value: 1}}
+	}
+	if unreadOnly {
+		filter["read"] = false
+	}
+
+	skip := int64((page - 1) * limit)
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(sort)
+
+	var bookmarks []domain.Bookmark
+
+	cursor, err := r.bookmarks.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed fetching bookmarks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &bookmarks); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding bookmarks: %w", err)
+	}
+
+	total, err := r.bookmarks.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting bookmarks: %w", err)
+	}
+
+	return bookmarks, int(total), nil
+}
+
+func (r *bookmarkRepository) GetBookmarkByID(ctx context.Context, userID string, id string) (*domain.Bookmark, error) {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return nil, errors.New("bookmark not found")
+	}
+
+	var bookmark domain.Bookmark
+	if err := r.bookmarks.FindOne(ctx, filter).Decode(&bookmark); err != nil {
+		return nil, errors.New("bookmark not found")
+	}
+
+	return &bookmark, nil
+}
+
+func (r *bookmarkRepository) GetListBookmarkIDs(ctx context.Context, userID string, listID string) ([]string, error) {
+	filter, err := listFilter(userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.D{{Key: "position", Value: 1}})
+
+	cursor, err := r.bookmarks.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching bookmarks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var bookmarks []domain.Bookmark
+	if err := cursor.All(ctx, &bookmarks); err != nil {
+		return nil, fmt.Errorf("failed decoding bookmarks: %w", err)
+	}
+
+	ids := make([]string, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		ids = append(ids, b.ID.Hex())
+	}
+
+	return ids, nil
+}
+
+// NextPosition returns the position after the last bookmark of a reading list
+func (r *bookmarkRepository) NextPosition(ctx context.Context, userID string, listID string) (int, error) {
+	filter, err := listFilter(userID, listID)
+	if err != nil {
+		return 0, err
+	}
+
+	var last domain.Bookmark
+	err = r.bookmarks.FindOne(
+		ctx,
+		filter,
+		options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}}),
+	).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed fetching bookmarks: %w", err)
+	}
+
+	return last.Position + 1, nil
+}
+
+// MoveBookmark files a bookmark in a reading list at the given position; an empty listID unfiles it
+func (r *bookmarkRepository) MoveBookmark(ctx context.Context, userID string, id string, listID string, position int) error {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return errors.New("bookmark not found")
+	}
+
+	update := bson.M{"$unset": bson.M{"list_id": ""}, "$set": bson.M{"position": 0}}
+	if listID != "" {
+		listObjID, err := primitive.ObjectIDFromHex(listID)
+		if err != nil {
+			return errors.New("reading list not found")
+		}
+		update = bson.M{"$set": bson.M{"list_id": listObjID, "position": position}}
+	}
+
+	res, err := r.bookmarks.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to move bookmark: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("bookmark not found")
+	}
+
+	return nil
+}
+
+func (r *bookmarkRepository) SetBookmarkRead(ctx context.Context, userID string, id string, read bool) error {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return errors.New("bookmark not found")
+	}
+
+	update := bson.M{"$set": bson.M{"read": false}, "$unset": bson.M{"read_at": ""}}
+	if read {
+		update = bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}}
+	}
+
+	res, err := r.bookmarks.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("bookmark not found")
+	}
+
+	return nil
+}
+
+// SetBookmarkPositions numbers the given bookmarks 0, 1, 2... in the order they are passed
+func (r *bookmarkRepository) SetBookmarkPositions(ctx context.Context, userID string, ids []string) error {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(ids))
+	for i, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return errors.New("bookmark not found")
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": objID, "user_id": userObjID}).
+			SetUpdate(bson.M{"$set": bson.M{"position": i}}))
+	}
+
+	if _, err := r.bookmarks.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to reorder bookmarks: %w", err)
+	}
+
+	return nil
+}
+
+func (r *bookmarkRepository) DeleteBookmark(ctx context.Context, userID string, id string) error {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return errors.New("bookmark not found")
+	}
+
+	res, err := r.bookmarks.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete bookmark: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("bookmark not found")
+	}
+
+	return nil
+}
+
+func (r *bookmarkRepository) DeleteBookmarksByBlogID(ctx context.Context, blogID string) error {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	if _, err := r.bookmarks.DeleteMany(ctx, bson.M{"blog_id": blogObjID}); err != nil {
+		return fmt.Errorf("failed to delete bookmarks: %w", err)
+	}
+
+	return nil
+}
+
+func (r *bookmarkRepository) CreateReadingList(ctx context.Context, list domain.ReadingList) (*domain.ReadingList, error) {
+	list.ID = primitive.NewObjectID()
+
+	if _, err := r.readingLists.InsertOne(ctx, list); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("reading list already exists")
+		}
+		return nil, fmt.Errorf("failed to insert reading list: %w", err)
+	}
+
+	return &list, nil
+}
+
+func (r *bookmarkRepository) GetReadingLists(ctx context.Context, userID string) ([]domain.ReadingList, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.readingLists.Find(ctx, bson.M{"user_id": userObjID}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching reading lists: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var lists []domain.ReadingList
+	if err := cursor.All(ctx, &lists); err != nil {
+		return nil, fmt.Errorf("failed decoding reading lists: %w", err)
+	}
+
+	return lists, nil
+}
+
+func (r *bookmarkRepository) GetReadingListByID(ctx context.Context, userID string, id string) (*domain.ReadingList, error) {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return nil, errors.New("reading list not found")
+	}
+
+	var list domain.ReadingList
+	if err := r.readingLists.FindOne(ctx, filter).Decode(&list); err != nil {
+		return nil, errors.New("reading list not found")
+	}
+
+	return &list, nil
+}
+
+func (r *bookmarkRepository) GetReadingListByShareToken(ctx context.Context, token string) (*domain.ReadingList, error) {
+	var list domain.ReadingList
+	if err := r.readingLists.FindOne(ctx, bson.M{"share_token": token}).Decode(&list); err != nil {
+		return nil, errors.New("reading list not found")
+	}
+
+	return &list, nil
+}
+
+func (r *bookmarkRepository) UpdateReadingList(ctx context.Context, userID string, id string, input domain.ReadingListInput) error {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return errors.New("reading list not found")
+	}
+
+	update := bson.M{"$set": bson.M{
+		"name":        input.Name,
+		"description": input.Description,
+		"updated":     time.Now(),
+	}}
+
+	res, err := r.readingLists.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("reading list already exists")
+		}
+		return fmt.Errorf("failed to update reading list: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("reading list not found")
+	}
+
+	return nil
+}
+
+// SetShareToken sets the token of a reading list's public link; an empty token stops sharing it
+func (r *bookmarkRepository) SetShareToken(ctx context.Context, userID string, id string, token string) error {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return errors.New("reading list not found")
+	}
+
+	update := bson.M{"$unset": bson.M{"share_token": ""}, "$set": bson.M{"updated": time.Now()}}
+	if token != "" {
+		update = bson.M{"$set": bson.M{"share_token": token, "updated": time.Now()}}
+	}
+
+	res, err := r.readingLists.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update reading list: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("reading list not found")
+	}
+
+	return nil
+}
+
+// DeleteReadingList removes the list; its bookmarks are kept and become unfiled
+func (r *bookmarkRepository) DeleteReadingList(ctx context.Context, userID string, id string) error {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return errors.New("reading list not found")
+	}
+
+	res, err := r.readingLists.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete reading list: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("reading list not found")
+	}
+
+	_, err = r.bookmarks.UpdateMany(
+		ctx,
+		bson.M{"user_id": filter["user_id"], "list_id": filter["_id"]},
+		bson.M{"$unset": bson.M{"list_id": ""}, "$set": bson.M{"position": 0}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to unfile bookmarks: %w", err)
+	}
+
+	return nil
+}
+
+func (r *bookmarkRepository) EnsureIndexes(ctx context.Context) error {
+	bookmarkIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "blog_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "list_id", Value: 1}, {Key: "position", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "blog_id", Value: 1}}, // for cleaning up after a blog is deleted
+		},
+	}
+	if _, err := r.bookmarks.Indexes().CreateMany(ctx, bookmarkIndexes); err != nil {
+		return err
+	}
+
+	listIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "share_token", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	}
+	_, err := r.readingLists.Indexes().CreateMany(ctx, listIndexes)
+	return err
+}
+
+// ownedFilter matches the document with the given ID only if it belongs to the user
+func ownedFilter(userID string, id string) (bson.M, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"_id": objID, "user_id": userObjID}, nil
+}
+
+// listFilter matches the user's bookmarks filed in a reading list
+func listFilter(userID string, listID string) (bson.M, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	listObjID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return nil, errors.New("reading list not found")
+	}
+	return bson.M{"user_id": userObjID, "list_id": listObjID}, nil
+}
@@ -55,10 +55,11 @@ func (r *commentRepository) CreateComment(ctx context.Context, blogID string, us
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert comment: %w", err)
 	}
-	update := bson.M{"$inc": bson.M{"comments_count": 1}}
-	_, err = r.blogCollection.UpdateByID(ctx, blogObjID, update)
-	if err != nil {
-		return nil, fmt.Errorf("failed to increment comment count: %w", err)
+	// pending comments are only counted once they are approved
+	if comment.IsApproved() {
+		if err := r.countApproved(ctx, &comment); err != nil {
+			return nil, err
+		}
 	}
 
 	 r.commentCache.Invalidate(blogID)
@@ -66,8 +67,38 @@ func (r *commentRepository) CreateComment(ctx context.Context, blogID string, us
 	return &comment, nil
 }
 
-func (r *commentRepository) GetAllComments(ctx context.Context, blogID string, page int, limit int, sort string) ([]*domain.Comment, int, error) {
-	cacheKey := fmt.Sprintf("comments:%s:%d:%d:%s", blogID, page, limit, sort)
+// countApproved adds a newly visible comment to its parent's reply count and its blog's comment count
+func (r *commentRepository) countApproved(ctx context.Context, comment *domain.Comment) error {
+	if comment.ParentID != nil {
+		_, err := r.collection.UpdateByID(ctx, *comment.ParentID, bson.M{"$inc": bson.M{"reply_count": 1}})
+		if err != nil {
+			return fmt.Errorf("failed to increment reply count: %w", err)
+		}
+	}
+	update := bson.M{"$inc": bson.M{"comments_count": 1}}
+	_, err := r.blogCollection.UpdateByID(ctx, comment.BlogID, update)
+	if err != nil {
+		return fmt.Errorf("failed to increment comment count: %w", err)
+	}
+	return nil
+}
+
+// visibleTo limits a comment query to approved comments that are not hidden,
+// plus the viewer's own pending, rejected or hidden ones
+func visibleTo(filter bson.M, viewerID string) bson.M {
+	visible := []bson.M{{
+		"status": bson.M{"$in": []interface{}{domain.CommentStatusApproved, nil}},
+		"hidden": bson.M{"$ne": true},
+	}}
+	if viewerObjID, err := primitive.ObjectIDFromHex(viewerID); err == nil {
+		visible = append(visible, bson.M{"user_id": viewerObjID})
+	}
+	filter["$or"] = visible
+	return filter
+}
+
+func (r *commentRepository) GetAllComments(ctx context.Context, blogID string, viewerID string, page int, limit int, sort string) ([]*domain.Comment, int, error) {
+	cacheKey := fmt.Sprintf("comments:%s:%s:%d:%d:%s", blogID, viewerID, page, limit, sort)
 	if comments,found := r.commentCache.Get(cacheKey); found{
 		log.Println("cache hit for getting all comments")
 		return comments,len(comments), nil
@@ -91,7 +122,8 @@ func (r *commentRepository) GetAllComments(ctx context.Context, blogID string, p
 	if err != nil {
 		return nil, 0, fmt.Errorf("invalid blog ID: %w", err)
 	}
-	filter := bson.M{"blog_id": blogObjID}
+	// only top-level comments; replies are fetched per comment with GetReplies
+	filter := visibleTo(bson.M{"blog_id": blogObjID, "parent_id": nil}, viewerID)
 
 	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
@@ -112,6 +144,54 @@ func (r *commentRepository) GetAllComments(ctx context.Context, blogID string, p
 	return comments, int(totalCount), nil
 }
 
+func (r *commentRepository) GetReplies(ctx context.Context, blogID string, parentID string, viewerID string, page int, limit int, sort string) ([]*domain.Comment, int, error) {
+	cacheKey := fmt.Sprintf("replies:%s:%s:%s:%d:%d:%s", blogID, parentID, viewerID, page, limit, sort)
+	if replies, found := r.commentCache.Get(cacheKey); found {
+		log.Println("cache hit for getting replies")
+		return replies, len(replies), nil
+	}
+
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid blog ID: %w", err)
+	}
+	parentObjID, err := primitive.ObjectIDFromHex(parentID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid comment ID: %w", err)
+	}
+
+	var replies []*domain.Comment
+
+	skip := int64((page - 1) * limit)
+	findOptions := options.Find().SetSkip(skip).SetLimit(int64(limit))
+	if sort == "latest" {
+		findOptions.SetSort(bson.D{{Key: "created", Value: -1}})
+	} else {
+		// replies read top to bottom as a conversation by default
+		findOptions.SetSort(bson.D{{Key: "created", Value: 1}})
+	}
+
+	filter := visibleTo(bson.M{"blog_id": blogObjID, "parent_id": parentObjID}, viewerID)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch replies from DB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &replies); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode replies: %w", err)
+	}
+
+	totalCount, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count replies: %w", err)
+	}
+	r.commentCache.SetWithSortKey(blogID, cacheKey, replies)
+
+	return replies, int(totalCount), nil
+}
+
 func (r *commentRepository) GetCommentByID(ctx context.Context, blogID string, id string) (*domain.Comment, error) {
 	objId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -131,7 +211,7 @@ func (r *commentRepository) GetCommentByID(ctx context.Context, blogID string, i
 	return &comment, nil
 }
 
-func (r *commentRepository) EditComment(ctx context.Context, blogID string, id string, userID string, message string) error {
+func (r *commentRepository) EditComment(ctx context.Context, blogID string, id string, userID string, message string, messageHTML string) error {
 	objId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid comment ID: %w", err)
@@ -145,11 +225,12 @@ func (r *commentRepository) EditComment(ctx context.Context, blogID string, id s
 		return fmt.Errorf("invalid user ID: %w", err)
 	}
 
-	filter := bson.M{"_id": objId, "blog_id": blogObjID, "user_id": userObjID}
+	filter := bson.M{"_id": objId, "blog_id": blogObjID, "user_id": userObjID, "deleted": bson.M{"$ne": true}}
 	update := bson.M{
 		"$set": bson.M{
-			"message":    message,
-			"updated_at": time.Now(),
+			"message":      message,
+			"message_html": messageHTML,
+			"updated_at":   time.Now(),
 		},
 	}
 	res, err := r.collection.UpdateOne(ctx, filter, update)
@@ -179,14 +260,73 @@ func (r *commentRepository) DeleteComment(ctx context.Context, blogID, commentID
 	}
 
 	filter := bson.M{"_id": commentObjID, "blog_id": blogObjID, "user_id": userObjID}
-	res, err := r.collection.DeleteOne(ctx, filter)
+	return r.removeComment(ctx, filter, blogObjID, blogID)
+}
+
+func (r *commentRepository) DeleteCommentByID(ctx context.Context, blogID, commentID string) error {
+	commentObjID, err := primitive.ObjectIDFromHex(commentID)
 	if err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
+		return fmt.Errorf("invalid comment ID: %w", err)
 	}
-	if res.DeletedCount == 0 {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	filter := bson.M{"_id": commentObjID, "blog_id": blogObjID}
+	return r.removeComment(ctx, filter, blogObjID, blogID)
+}
+
+// removeComment deletes the matching comment. A comment that still has replies is
+// turned into a "[deleted]" tombstone instead so its replies stay in the thread.
+func (r *commentRepository) removeComment(ctx context.Context, filter bson.M, blogObjID primitive.ObjectID, blogID string) error {
+	filter["deleted"] = bson.M{"$ne": true}
+
+	var comment domain.Comment
+	if err := r.collection.FindOne(ctx, filter).Decode(&comment); err != nil {
 		return errors.New("comment not found")
 	}
 
+	// comments that were never approved are not part of any count
+	if !comment.IsApproved() {
+		if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": comment.ID}); err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+		if err := r.detachReply(ctx, comment.ParentID, false); err != nil {
+			return err
+		}
+		r.commentCache.Invalidate(blogID)
+		return nil
+	}
+
+	// reply_count only counts approved replies, so replies awaiting moderation are looked up as well
+	hasReplies, err := r.hasReplies(ctx, &comment)
+	if err != nil {
+		return err
+	}
+
+	if hasReplies {
+		update := bson.M{
+			"$set": bson.M{
+				"message":      domain.DeletedCommentMessage,
+				"message_html": "",
+				"author_name":  "",
+				"deleted":      true,
+				"updated_at":   time.Now(),
+			},
+		}
+		if _, err := r.collection.UpdateByID(ctx, comment.ID, update); err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+	} else {
+		if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": comment.ID}); err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+		if err := r.detachReply(ctx, comment.ParentID, true); err != nil {
+			return err
+		}
+	}
+
 	// Decrement blog comments count
 	update := bson.M{"$inc": bson.M{"comments_count": -1}}
 	_, err = r.blogCollection.UpdateByID(ctx, blogObjID, update)
@@ -194,14 +334,127 @@ func (r *commentRepository) DeleteComment(ctx context.Context, blogID, commentID
 		return fmt.Errorf("failed to decrement comment count: %w", err)
 	}
 
-	 r.commentCache.Invalidate(blogID)
+	r.commentCache.Invalidate(blogID)
 
+	return nil
+}
 
+// detachReply decrements the reply count of a removed reply's parent when the reply was counted
+// and removes tombstones that are left without replies, walking up the thread
+func (r *commentRepository) detachReply(ctx context.Context, parentID *primitive.ObjectID, counted bool) error {
+	decrement := -1
+	if !counted {
+		decrement = 0
+	}
+
+	for parentID != nil {
+		var parent domain.Comment
+		err := r.collection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": *parentID},
+			bson.M{"$inc": bson.M{"reply_count": decrement}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&parent)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil
+			}
+			return fmt.Errorf("failed to decrement reply count: %w", err)
+		}
+
+		if !parent.Deleted || parent.ReplyCount > 0 {
+			return nil
+		}
+		if hasReplies, err := r.hasReplies(ctx, &parent); err != nil || hasReplies {
+			return err
+		}
+
+		if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": parent.ID}); err != nil {
+			return fmt.Errorf("failed to remove deleted comment: %w", err)
+		}
+		// tombstones were approved comments, so their parents counted them
+		parentID = parent.ParentID
+		decrement = -1
+	}
 	return nil
 }
 
-func (r *commentRepository) DeleteCommentByID(ctx context.Context, blogID, commentID string) error {
-	commentObjID, err := primitive.ObjectIDFromHex(commentID)
+// hasReplies reports whether any reply to the comment is left, whatever its moderation status
+func (r *commentRepository) hasReplies(ctx context.Context, comment *domain.Comment) (bool, error) {
+	count, err := r.collection.CountDocuments(
+		ctx,
+		bson.M{"blog_id": comment.BlogID, "parent_id": comment.ID},
+		options.Count().SetLimit(1),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to count replies: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *commentRepository) CountCommentsByBlogID(ctx context.Context, id string) (int, error) {
+	blogID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, fmt.Errorf("invalid comment ID: %w", err)
+	}
+	filter := bson.M{
+		"blog_id": blogID,
+		"deleted": bson.M{"$ne": true},
+		"hidden":  bson.M{"$ne": true},
+		"status":  bson.M{"$in": []interface{}{domain.CommentStatusApproved, nil}},
+	}
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count comments failed: %w", err)
+	}
+	return int(count), nil
+}
+
+// CountCommentsByBlogIDs counts the visible comments of each of the given blogs; blogs without any are left out
+func (r *commentRepository) CountCommentsByBlogIDs(ctx context.Context, ids []string) (map[string]int, error) {
+	blogIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		blogID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blog ID: %w", err)
+		}
+		blogIDs = append(blogIDs, blogID)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"blog_id": bson.M{"$in": blogIDs},
+			"deleted": bson.M{"$ne": true},
+			"hidden":  bson.M{"$ne": true},
+			"status":  bson.M{"$in": []interface{}{domain.CommentStatusApproved, nil}},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$blog_id", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("count comments failed: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		BlogID primitive.ObjectID `bson:"_id"`
+		Count  int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("count comments failed: %w", err)
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.BlogID.Hex()] = result.Count
+	}
+	return counts, nil
+}
+
+// SetCommentHidden hides a comment from everyone but its author, or makes it visible again
+func (r *commentRepository) SetCommentHidden(ctx context.Context, blogID string, id string, hidden bool) error {
+	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid comment ID: %w", err)
 	}
@@ -210,45 +463,145 @@ func (r *commentRepository) DeleteCommentByID(ctx context.Context, blogID, comme
 		return fmt.Errorf("invalid blog ID: %w", err)
 	}
 
-	filter := bson.M{"_id": commentObjID, "blog_id": blogObjID}
-	res, err := r.collection.DeleteOne(ctx, filter)
-	if err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
-	}
-	if res.DeletedCount == 0 {
-		return errors.New("comment not found")
+	update := bson.M{"$unset": bson.M{"hidden": ""}}
+	if hidden {
+		update = bson.M{"$set": bson.M{"hidden": true}}
 	}
 
-	// Decrement blog comments count
-	update := bson.M{"$inc": bson.M{"comments_count": -1}}
-	_, err = r.blogCollection.UpdateByID(ctx, blogObjID, update)
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID, "blog_id": blogObjID}, update)
 	if err != nil {
-		return fmt.Errorf("failed to decrement comment count: %w", err)
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("comment not found")
 	}
 
-	
 	r.commentCache.Invalidate(blogID)
-
 	return nil
 }
 
-func (r *commentRepository) CountCommentsByBlogID(ctx context.Context, id string) (int, error) {
-	blogID, err := primitive.ObjectIDFromHex(id)
+func (r *commentRepository) CountApprovedCommentsByUser(ctx context.Context, userID string) (int, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return 0, fmt.Errorf("invalid comment ID: %w", err)
+		return 0, fmt.Errorf("invalid user ID: %w", err)
+	}
+	filter := bson.M{
+		"user_id": userObjID,
+		"status":  bson.M{"$in": []interface{}{domain.CommentStatusApproved, nil}},
 	}
-	count, err := r.collection.CountDocuments(ctx, bson.M{"blog_id": blogID})
+	count, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return 0, fmt.Errorf("count comments failed: %w", err)
 	}
 	return int(count), nil
 }
 
+// GetPendingComments lists comments waiting for moderation, oldest first.
+// An empty blogAuthorID lists pending comments on every blog.
+func (r *commentRepository) GetPendingComments(ctx context.Context, blogAuthorID string, page int, limit int) ([]*domain.Comment, int, error) {
+	filter := bson.M{"status": domain.CommentStatusPending}
+	if blogAuthorID != "" {
+		authorObjID, err := primitive.ObjectIDFromHex(blogAuthorID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid user ID: %w", err)
+		}
+		filter["blog_author_id"] = authorObjID
+	}
+
+	skip := int64((page - 1) * limit)
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch pending comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var comments []*domain.Comment
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode comments: %w", err)
+	}
+
+	totalCount, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	return comments, int(totalCount), nil
+}
+
+// SetCommentsStatus moves pending comments to the given status and returns the comments it changed.
+// A non-empty blogAuthorID restricts the change to comments on that author's blogs.
+func (r *commentRepository) SetCommentsStatus(ctx context.Context, ids []string, blogAuthorID string, status string) ([]*domain.Comment, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comment ID: %w", err)
+		}
+		objIDs = append(objIDs, objID)
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": objIDs}, "status": domain.CommentStatusPending}
+	if blogAuthorID != "" {
+		authorObjID, err := primitive.ObjectIDFromHex(blogAuthorID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+		filter["blog_author_id"] = authorObjID
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending comments: %w", err)
+	}
+	var pending []*domain.Comment
+	if err := cursor.All(ctx, &pending); err != nil {
+		return nil, fmt.Errorf("failed to decode comments: %w", err)
+	}
+
+	moderated := make([]*domain.Comment, 0, len(pending))
+	for _, comment := range pending {
+		// re-check the status so a comment moderated concurrently is only counted once
+		update := bson.M{"$set": bson.M{"status": status}}
+		res, err := r.collection.UpdateOne(ctx, bson.M{"_id": comment.ID, "status": domain.CommentStatusPending}, update)
+		if err != nil {
+			return moderated, fmt.Errorf("failed to update comment status: %w", err)
+		}
+		if res.ModifiedCount == 0 {
+			continue
+		}
+
+		comment.Status = status
+		if status == domain.CommentStatusApproved {
+			if err := r.countApproved(ctx, comment); err != nil {
+				return moderated, err
+			}
+		}
+		r.commentCache.Invalidate(comment.BlogID.Hex())
+		moderated = append(moderated, comment)
+	}
+
+	return moderated, nil
+}
+
 func (r *commentRepository) EnsureIndexes(ctx context.Context) error {
 	indexModels := []mongo.IndexModel{
 		{
 			Keys: bson.D{{Key: "blog_id", Value: 1}}, // for counting comments
 		},
+		{
+			Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "created", Value: -1}}, // for listing threads
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "blog_author_id", Value: 1}, {Key: "created", Value: 1}}, // for the moderation queue
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}}, // for deciding whether a commenter is trusted
+		},
 	}
 
 	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
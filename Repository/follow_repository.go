@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoFollowRepo struct {
+	coll *mongo.Collection
+}
+
+func NewMongoFollowRepo(coll *mongo.Collection) domain.IFollowRepository {
+	return &mongoFollowRepo{coll: coll}
+}
+
+func (r *mongoFollowRepo) Follow(ctx context.Context, followerID, followeeID string) error {
+
+	followerObjID, followeeObjID, err := parseFollowIDs(followerID, followeeID)
+	if err != nil {
+		return err
+	}
+
+	follow := domain.Follow{
+		ID:         primitive.NewObjectID(),
+		FollowerID: followerObjID,
+		FolloweeID: followeeObjID,
+		Created:    time.Now(),
+	}
+
+	if _, err := r.coll.InsertOne(ctx, follow); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrAlreadyFollowing
+		}
+		return domain.ErrInternalServer
+	}
+
+	return nil
+}
+
+func (r *mongoFollowRepo) Unfollow(ctx context.Context, followerID, followeeID string) error {
+
+	followerObjID, followeeObjID, err := parseFollowIDs(followerID, followeeID)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.coll.DeleteOne(ctx, bson.M{"follower_id": followerObjID, "followee_id": followeeObjID})
+	if err != nil {
+		return domain.ErrInternalServer
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFollowing
+	}
+
+	return nil
+}
+
+func (r *mongoFollowRepo) CountFollowers(ctx context.Context, userID string) (int, error) {
+	return r.count(ctx, "followee_id", userID)
+}
+
+func (r *mongoFollowRepo) CountFollowing(ctx context.Context, userID string) (int, error) {
+	return r.count(ctx, "follower_id", userID)
+}
+
+func (r *mongoFollowRepo) count(ctx context.Context, field string, userID string) (int, error) {
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, domain.ErrInvalidUserID
+	}
+
+	count, err := r.coll.CountDocuments(ctx, bson.M{field: objID})
+	if err != nil {
+		return 0, domain.ErrInternalServer
+	}
+
+	return int(count), nil
+}
+
+func (r *mongoFollowRepo) GetFollowingIDs(ctx context.Context, userID string) ([]string, error) {
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidUserID
+	}
+
+	opts := options.Find().SetProjection(bson.M{"followee_id": 1})
+	cursor, err := r.coll.Find(ctx, bson.M{"follower_id": objID}, opts)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+	defer cursor.Close(ctx)
+
+	var follows []domain.Follow
+	if err := cursor.All(ctx, &follows); err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	ids := make([]string, 0, len(follows))
+	for _, f := range follows {
+		ids = append(ids, f.FolloweeID.Hex())
+	}
+
+	return ids, nil
+}
+
+func (r *mongoFollowRepo) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "followee_id", Value: 1}}, // for counting followers
+		},
+	}
+
+	_, err := r.coll.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
+
+func parseFollowIDs(followerID, followeeID string) (primitive.ObjectID, primitive.ObjectID, error) {
+
+	followerObjID, err := primitive.ObjectIDFromHex(followerID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, domain.ErrInvalidUserID
+	}
+	followeeObjID, err := primitive.ObjectIDFromHex(followeeID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, domain.ErrInvalidUserID
+	}
+
+	return followerObjID, followeeObjID, nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type jobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewJobRepository(coll *mongo.Collection) domain.JobRepository {
+	return &jobRepository{collection: coll}
+}
+
+func (r *jobRepository) CreateJob(ctx context.Context, job domain.Job) error {
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	job.Status = domain.JobStatusPending
+	job.Created = now
+	job.Updated = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	if _, err := r.collection.InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+// ClaimJob locks the oldest due job for the lease and counts the attempt; it returns nil when no job is due.
+// Jobs left running by a worker that died are due again once their lock has expired.
+// Every claim gets a new lease ID, so a worker whose lease ran out can no longer finish the job.
+func (r *jobRepository) ClaimJob(ctx context.Context, now time.Time, lease time.Duration) (*domain.Job, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": domain.JobStatusPending, "run_at": bson.M{"$lte": now}},
+		bson.M{"status": domain.JobStatusRunning, "locked_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       domain.JobStatusRunning,
+			"locked_until": now.Add(lease),
+			"lease_id":     primitive.NewObjectID(),
+			"updated":      now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job domain.Job
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// CompleteJob removes a job that succeeded, as long as the worker still holds its lease
+func (r *jobRepository) CompleteJob(ctx context.Context, id string, leaseID string) error {
+	filter, err := leasedJobFilter(id, leaseID)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("job lease expired")
+	}
+	return nil
+}
+
+// RetryJob puts a failed job back in the queue to run again at runAt, as long as the worker still holds its lease
+func (r *jobRepository) RetryJob(ctx context.Context, id string, leaseID string, runAt time.Time, lastError string) error {
+	return r.releaseJob(ctx, id, leaseID, bson.M{
+		"$set":   bson.M{"status": domain.JobStatusPending, "run_at": runAt, "last_error": lastError, "updated": time.Now()},
+		"$unset": bson.M{"locked_until": "", "lease_id": ""},
+	})
+}
+
+// BuryJob moves a job that failed its last attempt to the dead letters, as long as the worker still holds its lease
+func (r *jobRepository) BuryJob(ctx context.Context, id string, leaseID string, lastError string) error {
+	return r.releaseJob(ctx, id, leaseID, bson.M{
+		"$set":   bson.M{"status": domain.JobStatusDead, "last_error": lastError, "updated": time.Now()},
+		"$unset": bson.M{"locked_until": "", "lease_id": ""},
+	})
+}
+
+// releaseJob applies the update to a running job; once the lease has been taken over by another
+// worker the job is left alone, so the worker running it now decides what happens to it
+func (r *jobRepository) releaseJob(ctx context.Context, id string, leaseID string, update bson.M) error {
+	filter, err := leasedJobFilter(id, leaseID)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("job lease expired")
+	}
+	return nil
+}
+
+// GetJobs lists queued jobs, most recently updated first; an empty status includes every status
+func (r *jobRepository) GetJobs(ctx context.Context, status string, page int, limit int) ([]domain.Job, int, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting jobs: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed fetching jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []domain.Job{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding jobs: %w", err)
+	}
+
+	return jobs, int(total), nil
+}
+
+// RequeueJob gives a dead job a fresh set of attempts, starting now
+func (r *jobRepository) RequeueJob(ctx context.Context, id string) error {
+	return r.setJobStatus(ctx, id, bson.M{"status": domain.JobStatusDead}, requeueUpdate(time.Now()))
+}
+
+// RequeueDeadJobs gives every dead job a fresh set of attempts and returns how many were requeued
+func (r *jobRepository) RequeueDeadJobs(ctx context.Context) (int, error) {
+	res, err := r.collection.UpdateMany(ctx, bson.M{"status": domain.JobStatusDead}, requeueUpdate(time.Now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue jobs: %w", err)
+	}
+	return int(res.ModifiedCount), nil
+}
+
+func (r *jobRepository) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "run_at", Value: 1}}, // for workers claiming due jobs
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "locked_until", Value: 1}}, // for jobs whose worker died
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "updated", Value: -1}}, // for the admin job list
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
+
+// setJobStatus applies the update to the job if it is still in the expected state
+func (r *jobRepository) setJobStatus(ctx context.Context, id string, expected bson.M, update bson.M) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objID}
+	for key, value := range expected {
+		filter[key] = value
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("job not found")
+	}
+	return nil
+}
+
+// leasedJobFilter matches the job while it is running under the given lease
+func leasedJobFilter(id string, leaseID string) (bson.M, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID: %w", err)
+	}
+	leaseObjID, err := primitive.ObjectIDFromHex(leaseID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lease ID: %w", err)
+	}
+
+	return bson.M{"_id": objID, "status": domain.JobStatusRunning, "lease_id": leaseObjID}, nil
+}
+
+func requeueUpdate(now time.Time) bson.M {
+	return bson.M{
+		"$set": bson.M{"status": domain.JobStatusPending, "attempts": 0, "run_at": now, "updated": now},
+	}
+}
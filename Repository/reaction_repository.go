@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reactionRepository struct {
+	collection     *mongo.Collection
+	userRepository domain.IUserRepository
+}
+
+func NewReactionRepository(coll *mongo.Collection, userRepo domain.IUserRepository) domain.ReactionRepository {
+	return &reactionRepository{
+		collection:     coll,
+		userRepository: userRepo,
+	}
+}
+
+func (r *reactionRepository) AddReaction(ctx context.Context, blogID string, userID string, reactionType string) (*domain.Reaction, error) {
+	blogObjID, userObjID, err := parseBlogUserIDs(blogID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.userRepository.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	reaction := domain.Reaction{
+		ID:       primitive.NewObjectID(),
+		BlogID:   blogObjID,
+		UserID:   userObjID,
+		UserName: fmt.Sprintf("%s %s", user.Firstname, user.Lastname),
+		Type:     reactionType,
+		Created:  time.Now(),
+	}
+
+	// the unique index makes adding the same reaction twice fail atomically
+	if _, err := r.collection.InsertOne(ctx, reaction); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("already reacted")
+		}
+		return nil, fmt.Errorf("failed to insert reaction: %w", err)
+	}
+
+	return &reaction, nil
+}
+
+func (r *reactionRepository) RemoveReaction(ctx context.Context, blogID string, userID string, reactionType string) error {
+	blogObjID, userObjID, err := parseBlogUserIDs(blogID, userID)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"blog_id": blogObjID, "user_id": userObjID, "type": reactionType})
+	if err != nil {
+		return fmt.Errorf("failed to delete reaction: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("reaction not found")
+	}
+
+	return nil
+}
+
+// GetReactions lists who reacted to a blog, newest first; an empty reactionType includes every type
+func (r *reactionRepository) GetReactions(ctx context.Context, blogID string, reactionType string, page int, limit int) ([]domain.Reaction, int, error) {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	filter := bson.M{"blog_id": blogObjID}
+	if reactionType != "" {
+		filter["type"] = reactionType
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting reactions: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed fetching reactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	reactions := []domain.Reaction{}
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding reactions: %w", err)
+	}
+
+	return reactions, int(total), nil
+}
+
+// GetUserReactions returns the reaction types the user left, keyed by blog ID, for the blogs they reacted to
+func (r *reactionRepository) GetUserReactions(ctx context.Context, userID string, blogIDs []string) (map[string][]string, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	blogObjIDs, err := parseBlogIDs(blogIDs)
+	if err != nil {
+		return nil, err
+	}
+	reactions := make(map[string][]string)
+	if len(blogObjIDs) == 0 {
+		return reactions, nil
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userObjID, "blog_id": bson.M{"$in": blogObjIDs}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching reactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var found []domain.Reaction
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, fmt.Errorf("failed decoding reactions: %w", err)
+	}
+	for _, reaction := range found {
+		blogID := reaction.BlogID.Hex()
+		reactions[blogID] = append(reactions[blogID], reaction.Type)
+	}
+
+	return reactions, nil
+}
+
+func (r *reactionRepository) DeleteReactionsByBlogID(ctx context.Context, blogID string) error {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"blog_id": blogObjID}); err != nil {
+		return fmt.Errorf("failed to delete reactions: %w", err)
+	}
+	return nil
+}
+
+func (r *reactionRepository) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "type", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "type", Value: 1}, {Key: "created", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "blog_id", Value: 1}}, // for the requesting user's reactions on a page of blogs
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
+
+func parseBlogUserIDs(blogID, userID string) (primitive.ObjectID, primitive.ObjectID, error) {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, fmt.Errorf("invalid blog ID: %w", err)
+	}
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, fmt.Errorf("invalid user ID: %w", err)
+	}
+	return blogObjID, userObjID, nil
+}
+
+func parseBlogIDs(ids []string) ([]primitive.ObjectID, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blog ID: %w", err)
+		}
+		objIDs = append(objIDs, objID)
+	}
+	return objIDs, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reportRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportRepository(coll *mongo.Collection) domain.ReportRepository {
+	return &reportRepository{collection: coll}
+}
+
+func (r *reportRepository) CreateReport(ctx context.Context, report domain.Report) (*domain.Report, error) {
+	report.ID = primitive.NewObjectID()
+
+	if _, err := r.collection.InsertOne(ctx, report); err != nil {
+		// a reporter can only have one open report per target
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("already reported")
+		}
+		return nil, fmt.Errorf("failed to insert report: %w", err)
+	}
+
+	return &report, nil
+}
+
+func (r *reportRepository) CountOpenReports(ctx context.Context, targetType string, targetID string) (int, error) {
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid target ID: %w", err)
+	}
+
+	filter := bson.M{"target_type": targetType, "target_id": targetObjID, "status": domain.ReportStatusOpen}
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed counting reports: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// GetReportSummaries groups reports in the given status by target, most reported first.
+// An empty targetType includes every kind of target.
+func (r *reportRepository) GetReportSummaries(ctx context.Context, targetType string, status string, page int, limit int) ([]domain.ReportSummary, int, error) {
+	match := bson.M{"status": status}
+	if targetType != "" {
+		match["target_type"] = targetType
+	}
+
+	group := bson.D{{Key: "$group", Value: bson.M{
+		"_id":            bson.M{"target_type": "$target_type", "target_id": "$target_id"},
+		"blog_id":        bson.M{"$first": "$blog_id"},
+		"count":          bson.M{"$sum": 1},
+		"reasons":        bson.M{"$addToSet": "$reason"},
+		"first_reported": bson.M{"$min": "$created"},
+		"last_reported":  bson.M{"$max": "$created"},
+	}}}
+	project := bson.D{{Key: "$project", Value: bson.M{
+		"_id":            0,
+		"target_type":    "$_id.target_type",
+		"target_id":      "$_id.target_id",
+		"blog_id":        1,
+		"count":          1,
+		"reasons":        1,
+		"first_reported": 1,
+		"last_reported":  1,
+	}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		group,
+		project,
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "last_reported", Value: -1}}}},
+		{{Key: "$skip", Value: int64((page - 1) * limit)}},
+		{{Key: "$limit", Value: int64(limit)}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed aggregating reports: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var summaries []domain.ReportSummary
+	if err := cursor.All(ctx, &summaries); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding reports: %w", err)
+	}
+
+	// number of distinct targets, for pagination
+	countPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"target_type": "$target_type", "target_id": "$target_id"}}}},
+		{{Key: "$count", Value: "total"}},
+	}
+	countCursor, err := r.collection.Aggregate(ctx, countPipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting reports: %w", err)
+	}
+	defer countCursor.Close(ctx)
+
+	var counts []struct {
+		Total int `bson:"total"`
+	}
+	if err := countCursor.All(ctx, &counts); err != nil {
+		return nil, 0, fmt.Errorf("failed counting reports: %w", err)
+	}
+
+	total := 0
+	if len(counts) > 0 {
+		total = counts[0].Total
+	}
+
+	return summaries, total, nil
+}
+
+func (r *reportRepository) GetReportsByTarget(ctx context.Context, targetType string, targetID string) ([]domain.Report, error) {
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target ID: %w", err)
+	}
+
+	filter := bson.M{"target_type": targetType, "target_id": targetObjID}
+	findOptions := options.Find().SetSort(bson.D{{Key: "created", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching reports: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var reports []domain.Report
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, fmt.Errorf("failed decoding reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// ResolveReports closes every open report against a target with the given status
+func (r *reportRepository) ResolveReports(ctx context.Context, targetType string, targetID string, status string, resolvedBy string) (int, error) {
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid target ID: %w", err)
+	}
+	resolverObjID, err := primitive.ObjectIDFromHex(resolvedBy)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"target_type": targetType, "target_id": targetObjID, "status": domain.ReportStatusOpen}
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"resolved_by": resolverObjID,
+		"resolved_at": time.Now(),
+	}}
+
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve reports: %w", err)
+	}
+
+	return int(res.ModifiedCount), nil
+}
+
+func (r *reportRepository) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			// one open report per reporter and target; a dismissed report can be filed again
+			Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "reporter_id", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": domain.ReportStatusOpen}),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "target_type", Value: 1}, {Key: "created", Value: -1}}, // for the review queue
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// revisionInsertAttempts is how often a revision is renumbered when a concurrent edit took its version
+const revisionInsertAttempts = 3
+
+type revisionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRevisionRepository(coll *mongo.Collection) domain.BlogRevisionRepository {
+	return &revisionRepository{collection: coll}
+}
+
+func (r *revisionRepository) CreateRevision(ctx context.Context, revision domain.BlogRevision) (*domain.BlogRevision, error) {
+	for range revisionInsertAttempts {
+		// the latest version is looked up so revisions of a blog are numbered 1, 2, 3...
+		var latest domain.BlogRevision
+		err := r.collection.FindOne(
+			ctx,
+			bson.M{"blog_id": revision.BlogID},
+			options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+		).Decode(&latest)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("failed to fetch latest revision: %w", err)
+		}
+
+		revision.ID = primitive.NewObjectID()
+		revision.Version = latest.Version + 1
+
+		// the unique index rejects a version a concurrent edit took first, so the next one is tried
+		if _, err := r.collection.InsertOne(ctx, revision); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to insert revision: %w", err)
+		}
+
+		return &revision, nil
+	}
+
+	return nil, domain.ErrRevisionConflict
+}
+
+func (r *revisionRepository) GetRevisions(ctx context.Context, blogID string, page int, limit int) ([]domain.BlogRevision, int, error) {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	filter := bson.M{"blog_id": blogObjID}
+	skip := int64((page - 1) * limit)
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "version", Value: -1}})
+
+	var revisions []domain.BlogRevision
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed fetching revisions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, 0, fmt.Errorf("failed decoding revisions: %w", err)
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed counting revisions: %w", err)
+	}
+
+	return revisions, int(total), nil
+}
+
+func (r *revisionRepository) GetRevisionByID(ctx context.Context, blogID string, id string) (*domain.BlogRevision, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid revision ID: %w", err)
+	}
+
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	var revision domain.BlogRevision
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID, "blog_id": blogObjID}).Decode(&revision)
+	if err != nil {
+		return nil, fmt.Errorf("revision not found: %w", err)
+	}
+
+	return &revision, nil
+}
+
+func (r *revisionRepository) DeleteRevision(ctx context.Context, blogID string, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid revision ID: %w", err)
+	}
+
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID, "blog_id": blogObjID}); err != nil {
+		return fmt.Errorf("failed to delete revision: %w", err)
+	}
+
+	return nil
+}
+
+func (r *revisionRepository) DeleteRevisionsByBlogID(ctx context.Context, blogID string) error {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"blog_id": blogObjID}); err != nil {
+		return fmt.Errorf("failed to delete revisions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *revisionRepository) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRevokedTokenRepo struct {
+	coll *mongo.Collection
+}
+
+func NewMongoRevokedTokenRepo(coll *mongo.Collection) domain.IRevokedTokenRepo {
+	return &mongoRevokedTokenRepo{coll: coll}
+}
+
+// Revoke adds the token to the denylist; revoking a token twice is not an error
+func (r *mongoRevokedTokenRepo) Revoke(ctx context.Context, token *domain.RevokedToken) error {
+	filter := bson.M{"_id": token.TokenID}
+	update := bson.M{"$set": bson.M{"expires_at": token.ExpiresAt}}
+
+	_, err := r.coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRevokedTokenRepo) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	err := r.coll.FindOne(ctx, bson.M{"_id": tokenID}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *mongoRevokedTokenRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0), // entries go once their token has expired
+	})
+	return err
+}
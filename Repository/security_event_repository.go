@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSecurityEventRepo struct {
+	coll *mongo.Collection
+}
+
+func NewMongoSecurityEventRepo(coll *mongo.Collection) domain.ISecurityEventRepo {
+	return &mongoSecurityEventRepo{coll: coll}
+}
+
+func (r *mongoSecurityEventRepo) Create(ctx context.Context, event *domain.SecurityEvent) error {
+	event.ID = primitive.NewObjectID()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if _, err := r.coll.InsertOne(ctx, event); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindByUserID returns the latest security events of a user, newest first
+func (r *mongoSecurityEventRepo) FindByUserID(ctx context.Context, userID string, limit int) ([]domain.SecurityEvent, error) {
+
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidUserID
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.coll.Find(ctx, bson.M{"user_id": oid}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []domain.SecurityEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *mongoSecurityEventRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSigningKeyRepo struct {
+	coll *mongo.Collection
+}
+
+func NewMongoSigningKeyRepo(coll *mongo.Collection) domain.ISigningKeyRepo {
+	return &mongoSigningKeyRepo{coll: coll}
+}
+
+// GetKeys returns the keys that still verify tokens, newest first
+func (r *mongoSigningKeyRepo) GetKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"expires_at": bson.M{"$exists": false}},
+		bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+	}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []domain.SigningKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Rotate stores the new signing key and retires the others at retireAt; they keep verifying until then
+func (r *mongoSigningKeyRepo) Rotate(ctx context.Context, key *domain.SigningKey, retireAt time.Time) error {
+	key.Active = true
+	key.CreatedAt = time.Now()
+	if _, err := r.coll.InsertOne(ctx, key); err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": bson.M{"$ne": key.ID}, "active": true}
+	update := bson.M{"$set": bson.M{"active": false, "expires_at": retireAt}}
+	_, err := r.coll.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (r *mongoSigningKeyRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0), // retired keys go once the last token they signed has expired
+	})
+	return err
+}
@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	domain "github.com/gedyzed/blog-starter-project/Domain"
@@ -23,6 +22,7 @@ func NewMongoTokenRepository(coll *mongo.Collection) domain.ITokenRepo {
 	}
 }
 
+// Save stores the tokens of a session; tokens without an ID start a new session
 func (r *mongoTokenRepo) Save(ctx context.Context, tokens *domain.Token) error {
 
     oid, err := primitive.ObjectIDFromHex(tokens.UserID)
@@ -30,54 +30,142 @@ func (r *mongoTokenRepo) Save(ctx context.Context, tokens *domain.Token) error {
 		return domain.ErrIncorrectUserID
 	}
 
-	filter := bson.M{"user_id": oid}
+	now := time.Now()
+	if tokens.ID.IsZero() {
+		tokens.ID = primitive.NewObjectID()
+		tokens.CreatedAt = now
+	}
+	tokens.LastUsedAt = now
+
+	filter := bson.M{"_id": tokens.ID, "user_id": oid}
 	update := bson.M{
 		"$set": bson.M{
-			"access_token":   tokens.AccessToken,
-			"refresh_token":  tokens.RefreshToken,
-			"access_expiry":  tokens.AccessExpiry,
-			"refresh_expiry": tokens.RefreshExpiry,
-			"updated_at":     time.Now(),
+			"access_token":    tokens.AccessToken,
+			"refresh_token":   tokens.RefreshToken,
+			"access_expiry":   tokens.AccessExpiry,
+			"refresh_expiry":  tokens.RefreshExpiry,
+			"access_token_id": tokens.AccessTokenID,
+			"generation":      tokens.Generation,
+			"last_used_at":    now,
+			"updated_at":      now,
 		},
 		"$setOnInsert": bson.M{
-			"createdAt": time.Now(),
+			"user_agent": tokens.UserAgent,
+			"ip":         tokens.IP,
+			"created_at": now,
 		},
 	}
 
 	_, err = r.coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
 	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Rotate stores the new tokens of a session only if its refresh token is still previousRefreshToken,
+// so a refresh token can be exchanged once; it returns ErrTokenNotFound otherwise
+func (r *mongoTokenRepo) Rotate(ctx context.Context, tokens *domain.Token, previousRefreshToken string) error {
+
+	now := time.Now()
+	filter := bson.M{"_id": tokens.ID, "refresh_token": previousRefreshToken}
+	update := bson.M{
+		"$set": bson.M{
+			"access_token":    tokens.AccessToken,
+			"refresh_token":   tokens.RefreshToken,
+			"access_expiry":   tokens.AccessExpiry,
+			"refresh_expiry":  tokens.RefreshExpiry,
+			"access_token_id": tokens.AccessTokenID,
+			"generation":      tokens.Generation,
+			"last_used_at":    now,
+			"updated_at":      now,
+		},
+	}
+
+	result, err := r.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
 
+	if result.MatchedCount == 0 {
+		return domain.ErrTokenNotFound
 	}
 
+	tokens.LastUsedAt = now
 	return nil
 }
 
-func (r *mongoTokenRepo) FindByUserID(ctx context.Context, userID string) (*domain.Token, error) {
+func (r *mongoTokenRepo) FindByID(ctx context.Context, sessionID string) (*domain.Token, error) {
+
+	oid, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, domain.ErrTokenNotFound
+	}
+
+	return r.findOne(ctx, bson.M{"_id": oid})
+}
+
+// FindByUserID lists the sessions of a user that can still refresh their tokens, most recently used first
+func (r *mongoTokenRepo) FindByUserID(ctx context.Context, userID string) ([]domain.Token, error) {
 
 	oid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, domain.ErrInvalidUserID
 	}
 
-	var tokens domain.Token
-	err = r.coll.FindOne(ctx, bson.M{"user_id": oid}).Decode(&tokens)
+	filter := bson.M{"user_id": oid, "refresh_expiry": bson.M{"$gt": time.Now()}}
+	opts := options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}})
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, domain.ErrTokenNotFound
-		}
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	return &tokens, nil
+	sessions := []domain.Token{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
 }
 
+func (r *mongoTokenRepo) FindByRefreshToken(ctx context.Context, refreshToken string) (*domain.Token, error) {
+	return r.findOne(ctx, bson.M{"refresh_token": refreshToken})
+}
+
+// DeleteByID ends one session of the user
+func (r *mongoTokenRepo) DeleteByID(ctx context.Context, userID, sessionID string) error {
+	userOID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+	sessionOID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return domain.ErrTokenNotFound
+	}
+
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": sessionOID, "user_id": userOID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteByUserID ends every session of the user
 func (r *mongoTokenRepo) DeleteByUserID(ctx context.Context, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return domain.ErrInvalidUserID
 	}
 
-	result, err := r.coll.DeleteOne(ctx, bson.M{"user_id": objID})
+	result, err := r.coll.DeleteMany(ctx, bson.M{"user_id": objID})
 	if err != nil {
 		return err
 	}
@@ -89,20 +177,43 @@ func (r *mongoTokenRepo) DeleteByUserID(ctx context.Context, userID string) erro
 	return nil
 }
 
-func (r *mongoTokenRepo) FindByAccessToken (ctx context.Context, accessToken string) (string, error) {
+func (r *mongoTokenRepo) FindByAccessToken (ctx context.Context, accessToken string) (*domain.Token, error) {
+	return r.findOne(ctx, bson.M{"access_token": accessToken})
+}
+
+func (r *mongoTokenRepo) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_used_at", Value: -1}}, // for listing a user's sessions
+		},
+		{
+			Keys: bson.D{{Key: "access_token", Value: 1}}, // for Google sessions, which are looked up by access token
+		},
+		{
+			Keys: bson.D{{Key: "refresh_token", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "refresh_expiry", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // sessions that can no longer refresh are removed
+		},
+	}
+
+	_, err := r.coll.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
+
+func (r *mongoTokenRepo) findOne(ctx context.Context, filter bson.M) (*domain.Token, error) {
 
 	var tokens domain.Token
-	err := r.coll.FindOne(ctx, bson.M{"access_token": accessToken}).Decode(&tokens)
+	err := r.coll.FindOne(ctx, filter).Decode(&tokens)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return "", domain.ErrTokenNotFound
+			return nil, domain.ErrTokenNotFound
 		}
-		return "", err
+		return nil, err
 	}
 
-	userID := tokens.UserID
-	fmt.Println("userID in access Token : ", userID)
-	return userID, nil
+	return &tokens, nil
 }
 
 
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type viewRepository struct {
+	collection *mongo.Collection
+}
+
+func NewViewRepository(coll *mongo.Collection) domain.ViewRepository {
+	return &viewRepository{collection: coll}
+}
+
+// RecordView remembers that the visitor saw the blog and reports whether it is their first view within the window.
+// A visitor's record only matches once it has expired, so a repeated view within the window falls through
+// to an insert that the unique index rejects.
+func (r *viewRepository) RecordView(ctx context.Context, blogID string, visitor string, window time.Duration) (bool, error) {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return false, fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"blog_id":    blogObjID,
+		"visitor":    visitor,
+		"expires_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"expires_at": now.Add(window)}}
+
+	_, err = r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record view: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *viewRepository) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "visitor", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// records are removed once their window is over
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
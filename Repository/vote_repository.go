@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type voteRepository struct {
+	collection     *mongo.Collection
+	blogCollection *mongo.Collection
+}
+
+func NewVoteRepository(coll *mongo.Collection, blogColl *mongo.Collection) domain.VoteRepository {
+	return &voteRepository{
+		collection:     coll,
+		blogCollection: blogColl,
+	}
+}
+
+// ToggleVote casts, switches or takes back a user's vote and returns how the blog's like and dislike counts change.
+// Every step is a single-document write, so concurrent requests of the same user cannot double count.
+func (r *voteRepository) ToggleVote(ctx context.Context, blogID string, userID string, voteType string) (int, int, error) {
+	blogObjID, userObjID, err := parseBlogUserIDs(blogID, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// voting the same way again takes the vote back
+	res, err := r.collection.DeleteOne(ctx, bson.M{"blog_id": blogObjID, "user_id": userObjID, "type": voteType})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to delete vote: %w", err)
+	}
+	if res.DeletedCount == 1 {
+		likes, dislikes := voteDelta(voteType, -1)
+		return likes, dislikes, nil
+	}
+
+	// otherwise the vote is cast, replacing an opposite vote in the same write
+	update := bson.M{
+		"$set":         bson.M{"type": voteType},
+		"$setOnInsert": bson.M{"created_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous domain.Vote
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"blog_id": blogObjID, "user_id": userObjID}, update, opts).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		likes, dislikes := voteDelta(voteType, 1)
+		return likes, dislikes, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to save vote: %w", err)
+	}
+	if previous.Type == voteType {
+		// a concurrent request already cast the same vote
+		return 0, 0, nil
+	}
+
+	likes, dislikes := voteDelta(voteType, 1)
+	oldLikes, oldDislikes := voteDelta(previous.Type, -1)
+	return likes + oldLikes, dislikes + oldDislikes, nil
+}
+
+// GetUserVotes returns the user's vote type keyed by blog ID, for the blogs they voted on
+func (r *voteRepository) GetUserVotes(ctx context.Context, userID string, blogIDs []string) (map[string]string, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	blogObjIDs, err := parseBlogIDs(blogIDs)
+	if err != nil {
+		return nil, err
+	}
+	votes := make(map[string]string)
+	if len(blogObjIDs) == 0 {
+		return votes, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userObjID, "blog_id": bson.M{"$in": blogObjIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching votes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var found []domain.Vote
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, fmt.Errorf("failed decoding votes: %w", err)
+	}
+	for _, v := range found {
+		votes[v.BlogID.Hex()] = v.Type
+	}
+
+	return votes, nil
+}
+
+func (r *voteRepository) DeleteVotesByBlogID(ctx context.Context, blogID string) error {
+	blogObjID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return fmt.Errorf("invalid blog ID: %w", err)
+	}
+
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"blog_id": blogObjID}); err != nil {
+		return fmt.Errorf("failed to delete votes: %w", err)
+	}
+	return nil
+}
+
+// MigrateBlogVotes moves the liked_users and disliked_users arrays of older blogs into the votes collection,
+// recounts their likes and dislikes from it and drops the arrays. It returns the number of blogs migrated
+// and is safe to run again.
+func (r *voteRepository) MigrateBlogVotes(ctx context.Context) (int, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"liked_users": bson.M{"$exists": true}},
+		bson.M{"disliked_users": bson.M{"$exists": true}},
+	}}
+	opts := options.Find().SetProjection(bson.M{"liked_users": 1, "disliked_users": 1})
+
+	cursor, err := r.blogCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var legacy struct {
+			ID            primitive.ObjectID `bson:"_id"`
+			LikedUsers    []string           `bson:"liked_users"`
+			DislikedUsers []string           `bson:"disliked_users"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return migrated, fmt.Errorf("failed decoding blog: %w", err)
+		}
+
+		if err := r.migrateBlog(ctx, legacy.ID, legacy.LikedUsers, legacy.DislikedUsers); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	if err := cursor.Err(); err != nil {
+		return migrated, fmt.Errorf("failed fetching blogs: %w", err)
+	}
+
+	return migrated, nil
+}
+
+func (r *voteRepository) migrateBlog(ctx context.Context, blogID primitive.ObjectID, likedUsers, dislikedUsers []string) error {
+	now := time.Now()
+	voted := make(map[primitive.ObjectID]bool)
+	var votes []interface{}
+
+	// the old toggles could leave a user in both arrays; their like wins
+	add := func(users []string, voteType string) {
+		for _, id := range users {
+			userObjID, err := primitive.ObjectIDFromHex(id)
+			if err != nil || voted[userObjID] {
+				continue
+			}
+			voted[userObjID] = true
+			votes = append(votes, domain.Vote{BlogID: blogID, UserID: userObjID, Type: voteType, CreatedAt: now})
+		}
+	}
+	add(likedUsers, domain.VoteLike)
+	add(dislikedUsers, domain.VoteDislike)
+
+	if len(votes) > 0 {
+		// votes already copied by an earlier, interrupted run are skipped by the unique index
+		_, err := r.collection.InsertMany(ctx, votes, options.InsertMany().SetOrdered(false))
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to insert votes: %w", err)
+		}
+	}
+
+	likes, err := r.collection.CountDocuments(ctx, bson.M{"blog_id": blogID, "type": domain.VoteLike})
+	if err != nil {
+		return fmt.Errorf("failed counting votes: %w", err)
+	}
+	dislikes, err := r.collection.CountDocuments(ctx, bson.M{"blog_id": blogID, "type": domain.VoteDislike})
+	if err != nil {
+		return fmt.Errorf("failed counting votes: %w", err)
+	}
+
+	update := bson.M{
+		"$set":   bson.M{"likes": likes, "dislikes": dislikes},
+		"$unset": bson.M{"liked_users": "", "disliked_users": ""},
+	}
+	if _, err := r.blogCollection.UpdateByID(ctx, blogID, update); err != nil {
+		return fmt.Errorf("failed to update blog votes: %w", err)
+	}
+
+	return nil
+}
+
+func (r *voteRepository) EnsureIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "blog_id", Value: 1}}, // for the requesting user's votes on a page of blogs
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
+
+// voteDelta is the change to a blog's like and dislike counts when n votes of the given type are added
+func voteDelta(voteType string, n int) (int, int) {
+	if voteType == domain.VoteLike {
+		return n, 0
+	}
+	return 0, n
+}
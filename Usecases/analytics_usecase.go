@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+const (
+	day = 24 * time.Hour
+
+	maxHourlyRange     = 31 * day  // longest period shown hour by hour
+	maxDailyRange      = 366 * day // longest period shown day by day
+	dashboardTopBlogs  = 10
+	defaultHourlyRange = 2 * day
+	defaultDailyRange  = 30 * day
+)
+
+type analyticsUsecase struct {
+	analyticsRepo domain.AnalyticsRepository
+	blogRepo      domain.BlogRepository
+}
+
+func NewAnalyticsUsecase(analyticsRepo domain.AnalyticsRepository, blogRepo domain.BlogRepository) domain.AnalyticsUsecase {
+	return &analyticsUsecase{
+		analyticsRepo: analyticsRepo,
+		blogRepo:      blogRepo,
+	}
+}
+
+func (uc *analyticsUsecase) GetBlogAnalytics(ctx context.Context, blogID string, userID string, query domain.AnalyticsQuery) (*domain.BlogAnalytics, error) {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, errors.New("blog not found")
+	}
+	if blog.UserID.Hex() != userID {
+		return nil, errors.New("unauthorized access")
+	}
+
+	return uc.blogAnalytics(ctx, blog, query)
+}
+
+func (uc *analyticsUsecase) GetBlogAnalyticsAsAdmin(ctx context.Context, blogID string, query domain.AnalyticsQuery) (*domain.BlogAnalytics, error) {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, errors.New("blog not found")
+	}
+
+	return uc.blogAnalytics(ctx, blog, query)
+}
+
+func (uc *analyticsUsecase) blogAnalytics(ctx context.Context, blog *domain.Blog, query domain.AnalyticsQuery) (*domain.BlogAnalytics, error) {
+	query, err := normalizeAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := uc.analyticsRepo.GetBlogSeries(ctx, blog.ID.Hex(), query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	series, totals := rollUpBuckets(buckets, query)
+	return &domain.BlogAnalytics{
+		BlogID:      blog.ID,
+		From:        query.From,
+		To:          query.To,
+		Granularity: query.Granularity,
+		Totals:      totals,
+		Series:      series,
+	}, nil
+}
+
+// GetAuthorDashboard sums the activity of all of the author's blogs and lists their most viewed blogs of the period
+func (uc *analyticsUsecase) GetAuthorDashboard(ctx context.Context, authorID string, query domain.AnalyticsQuery) (*domain.AuthorDashboard, error) {
+	query, err := normalizeAnalyticsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := uc.analyticsRepo.GetAuthorSeries(ctx, authorID, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	topBlogs, err := uc.analyticsRepo.GetAuthorTopBlogs(ctx, authorID, query.From, query.To, dashboardTopBlogs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range topBlogs {
+		if blog, err := uc.blogRepo.GetBlogByID(ctx, topBlogs[i].BlogID.Hex()); err == nil {
+			topBlogs[i].Title = blog.Title
+		}
+	}
+
+	series, totals := rollUpBuckets(buckets, query)
+	return &domain.AuthorDashboard{
+		From:        query.From,
+		To:          query.To,
+		Granularity: query.Granularity,
+		Totals:      totals,
+		Series:      series,
+		TopBlogs:    topBlogs,
+	}, nil
+}
+
+// normalizeAnalyticsQuery fills in the default period and aligns it to whole buckets
+func normalizeAnalyticsQuery(query domain.AnalyticsQuery) (domain.AnalyticsQuery, error) {
+	if query.Granularity == "" {
+		query.Granularity = domain.GranularityDay
+	}
+
+	step, maxRange, defaultRange := day, maxDailyRange, defaultDailyRange
+	switch query.Granularity {
+	case domain.GranularityDay:
+	case domain.GranularityHour:
+		step, maxRange, defaultRange = time.Hour, maxHourlyRange, defaultHourlyRange
+	default:
+		return query, errors.New("invalid granularity")
+	}
+
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultRange)
+	}
+
+	// the last bucket is included up to its end
+	query.From = query.From.UTC().Truncate(step)
+	if to := query.To.UTC().Truncate(step); to.Equal(query.To.UTC()) {
+		query.To = to
+	} else {
+		query.To = to.Add(step)
+	}
+
+	if !query.From.Before(query.To) {
+		return query, errors.New("from must be before to")
+	}
+	if query.To.Sub(query.From) > maxRange {
+		return query, errors.New("date range too large for granularity")
+	}
+
+	return query, nil
+}
+
+// rollUpBuckets folds hourly buckets into the query's granularity and returns a gapless series with its totals
+func rollUpBuckets(buckets []domain.StatBucket, query domain.AnalyticsQuery) ([]domain.StatBucket, domain.StatCounters) {
+	step := day
+	if query.Granularity == domain.GranularityHour {
+		step = time.Hour
+	}
+
+	byBucket := make(map[time.Time]domain.StatCounters, len(buckets))
+	var totals domain.StatCounters
+	for _, b := range buckets {
+		start := b.Bucket.UTC().Truncate(step)
+		byBucket[start] = byBucket[start].Add(b.StatCounters)
+		totals = totals.Add(b.StatCounters)
+	}
+
+	series := make([]domain.StatBucket, 0, int(query.To.Sub(query.From)/step))
+	for t := query.From; t.Before(query.To); t = t.Add(step) {
+		series = append(series, domain.StatBucket{Bucket: t, StatCounters: byBucket[t]})
+	}
+
+	return series, totals
+}
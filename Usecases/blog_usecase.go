@@ -2,37 +2,116 @@ package usecases
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"math"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	domain "github.com/gedyzed/blog-starter-project/Domain"
 )
 
+var validStatus = map[string]bool{
+	domain.BlogStatusDraft:     true,
+	domain.BlogStatusInReview:  true,
+	domain.BlogStatusScheduled: true,
+	domain.BlogStatusPublished: true,
+	domain.BlogStatusArchived:  true,
+}
+
+// statusTransitions lists the statuses a blog can move to from its current status
+var statusTransitions = map[string]map[string]bool{
+	domain.BlogStatusDraft:     {domain.BlogStatusInReview: true, domain.BlogStatusScheduled: true, domain.BlogStatusPublished: true},
+	domain.BlogStatusInReview:  {domain.BlogStatusDraft: true, domain.BlogStatusScheduled: true, domain.BlogStatusPublished: true},
+	domain.BlogStatusScheduled: {domain.BlogStatusDraft: true, domain.BlogStatusScheduled: true, domain.BlogStatusPublished: true},
+	domain.BlogStatusPublished: {domain.BlogStatusDraft: true, domain.BlogStatusArchived: true},
+	domain.BlogStatusArchived:  {domain.BlogStatusDraft: true, domain.BlogStatusPublished: true},
+}
+
+// ReactionPolicy holds the reactions users can leave on blogs and how much each adds to the popularity score
+type ReactionPolicy struct {
+	Types   []string
+	Weights map[string]float64 // types without a weight count as 1
+}
+
+// weights returns the weight of every allowed reaction type
+func (p ReactionPolicy) weights() map[string]float64 {
+	weights := make(map[string]float64, len(p.Types))
+	for _, t := range p.Types {
+		weights[t] = 1
+		if w, ok := p.Weights[t]; ok {
+			weights[t] = w
+		}
+	}
+	return weights
+}
+
+// TrendingPolicy decides how recent activity ranks blogs for sort=trending
+type TrendingPolicy struct {
+	HalfLife time.Duration // activity is worth half as much for every half-life that has passed
+	Window   time.Duration // activity older than this is left out
+	Weights  TrendingWeights
+}
+
+// TrendingWeights is what one of each kind of activity adds to the trending score
+type TrendingWeights struct {
+	UniqueViews float64
+	Likes       float64
+	Dislikes    float64
+	Comments    float64
+	Reactions   float64
+}
+
 type blogUsecase struct {
-	blogRepo    domain.BlogRepository
-	commentRepo domain.CommentRepository
-	dispatcher  domain.BlogRefreshDispatcher
+	blogRepo        domain.BlogRepository
+	commentRepo     domain.CommentRepository
+	revisionRepo    domain.BlogRevisionRepository
+	reactionRepo    domain.ReactionRepository
+	voteRepo        domain.VoteRepository
+	viewRepo        domain.ViewRepository
+	analyticsRepo   domain.AnalyticsRepository
+	dispatcher      domain.BlogRefreshDispatcher
+	markdown        domain.IMarkdownService
+	reactionWeights map[string]float64 // weight of every allowed reaction type
+	scoring         domain.ScoringStrategy
+	viewWindow      time.Duration // repeated views by the same visitor within this window count once
+	trending        TrendingPolicy
+	recomputing     atomic.Bool // set while every blog's score is being recomputed
 }
 
-func NewBlogUsecase(repo domain.BlogRepository, commentRepo domain.CommentRepository, dispatcher domain.BlogRefreshDispatcher) domain.BlogUsecase {
+func NewBlogUsecase(repo domain.BlogRepository, commentRepo domain.CommentRepository, revisionRepo domain.BlogRevisionRepository, reactionRepo domain.ReactionRepository, voteRepo domain.VoteRepository, viewRepo domain.ViewRepository, analyticsRepo domain.AnalyticsRepository, dispatcher domain.BlogRefreshDispatcher, markdown domain.IMarkdownService, reactions ReactionPolicy, scoring domain.ScoringStrategy, viewWindow time.Duration, trending TrendingPolicy) domain.BlogUsecase {
 	return &blogUsecase{
-		blogRepo:    repo,
-		commentRepo: commentRepo,
-		dispatcher:  dispatcher,
+		blogRepo:        repo,
+		commentRepo:     commentRepo,
+		revisionRepo:    revisionRepo,
+		reactionRepo:    reactionRepo,
+		voteRepo:        voteRepo,
+		viewRepo:        viewRepo,
+		analyticsRepo:   analyticsRepo,
+		dispatcher:      dispatcher,
+		markdown:        markdown,
+		reactionWeights: reactions.weights(),
+		scoring:         scoring,
+		viewWindow:      viewWindow,
+		trending:        trending,
 	}
 }
 
-func (uc *blogUsecase) GetAllBlogs(ctx context.Context, page int, limit int, sort string) (*domain.PaginatedBlogResponse, error) {
+func (uc *blogUsecase) GetAllBlogs(ctx context.Context, page int, limit int, sort string, viewerID string) (*domain.PaginatedBlogResponse, error) {
 	if page < 1 || limit < 1 {
 		return nil, fmt.Errorf("invalid pagination params")
 	}
 
-	blogs, totalCount, err := uc.blogRepo.GetAllBlogs(ctx, page, limit, sort)
+	blogs, totalCount, err := uc.blogRepo.GetAllBlogs(ctx, page, limit, sort, domain.BlogStatusPublished)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blogs: %w", err)
 	}
+	blogs = uc.withViewerState(ctx, viewerID, blogs)
 
 	totalPages := int(math.Ceil(float64(totalCount) / float64(limit)))
 
@@ -44,14 +123,131 @@ func (uc *blogUsecase) GetAllBlogs(ctx context.Context, page int, limit int, sor
 	}, nil
 }
 
-func (uc *blogUsecase) ViewBlog(ctx context.Context, id string) (*domain.Blog, error) {
-	blog, err := uc.blogRepo.GetBlogByID(ctx, id)
+func (uc *blogUsecase) GetMyBlogs(ctx context.Context, userID string, status string, page int, limit int) (*domain.PaginatedBlogResponse, error) {
+	if page < 1 || limit < 1 {
+		return nil, fmt.Errorf("invalid pagination params")
+	}
+	if status != "" && !validStatus[status] {
+		return nil, errors.New("invalid status")
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	blogs, totalCount, err := uc.blogRepo.GetBlogsByAuthor(ctx, userID, status, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blogs: %w", err)
+	}
+
+	totalPages := int(math.Ceil(float64(totalCount) / float64(limit)))
+
+	return &domain.PaginatedBlogResponse{
+		Blogs:       blogs,
+		TotalCount:  totalCount,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+	}, nil
+}
+
+func (uc *blogUsecase) ViewBlog(ctx context.Context, id string, viewer domain.Viewer) (*domain.Blog, error) {
+	cached, err := uc.blogRepo.GetBlogByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	_ = uc.blogRepo.IncrementBlogViews(ctx, id)
-	uc.dispatcher.Enqueue(id)
-	return blog, nil
+
+	// the blog may come from the cache and is shared between requests, so it is rendered into a copy
+	blog := cached
+	if blog.ContentHTML == "" && blog.Content != "" {
+		// blogs written before Markdown rendering existed are rendered on read
+		if html, err := uc.markdown.RenderBlog(blog.Content); err == nil {
+			rendered := *cached
+			rendered.ContentHTML = html
+			blog = &rendered
+		}
+	}
+
+	// unpublished blogs are only visible to their author and are not counted as views
+	if !blog.IsPublished() {
+		if blog.UserID.Hex() != viewer.UserID {
+			return nil, errors.New("blog not found")
+		}
+		return blog, nil
+	}
+
+	uc.countView(ctx, blog, viewer)
+
+	viewed := uc.withViewerState(ctx, viewer.UserID, []domain.Blog{*blog})
+	return &viewed[0], nil
+}
+
+// countView adds a view by a person; only the first view of a visitor within the view window is unique
+// and only unique views change the popularity score
+func (uc *blogUsecase) countView(ctx context.Context, blog *domain.Blog, viewer domain.Viewer) {
+	if isBot(viewer.UserAgent) {
+		return
+	}
+
+	id := blog.ID.Hex()
+	unique, err := uc.viewRepo.RecordView(ctx, id, visitorKey(viewer), uc.viewWindow)
+	if err != nil {
+		log.Println("failed to record view of blog", id, err)
+	}
+
+	_ = uc.blogRepo.IncrementBlogViews(ctx, id, unique)
+	delta := domain.StatCounters{Views: 1}
+	if unique {
+		delta.UniqueViews = 1
+		uc.dispatcher.Enqueue(id)
+	}
+	uc.record(ctx, blog, delta)
+}
+
+// record adds activity to the blog's analytics; a failure is only logged and never fails the request
+func (uc *blogUsecase) record(ctx context.Context, blog *domain.Blog, delta domain.StatCounters) {
+	if err := uc.analyticsRepo.Record(ctx, blog.ID.Hex(), blog.UserID.Hex(), time.Now(), delta); err != nil {
+		log.Println("failed to record analytics of blog", blog.ID.Hex(), err)
+	}
+}
+
+// withViewerState returns copies of the blogs marked with the viewer's vote and reactions.
+// Blogs may come from the cache and are shared between requests, so they are never marked in place.
+func (uc *blogUsecase) withViewerState(ctx context.Context, viewerID string, blogs []domain.Blog) []domain.Blog {
+	if viewerID == "" || len(blogs) == 0 {
+		return blogs
+	}
+
+	ids := make([]string, 0, len(blogs))
+	for _, b := range blogs {
+		ids = append(ids, b.ID.Hex())
+	}
+
+	votes, err := uc.voteRepo.GetUserVotes(ctx, viewerID, ids)
+	if err != nil {
+		log.Println("failed to get votes of viewer", viewerID, err)
+		return blogs
+	}
+	reactions, err := uc.reactionRepo.GetUserReactions(ctx, viewerID, ids)
+	if err != nil {
+		log.Println("failed to get reactions of viewer", viewerID, err)
+		return blogs
+	}
+
+	marked := make([]domain.Blog, len(blogs))
+	copy(marked, blogs)
+	for i := range marked {
+		id := marked[i].ID.Hex()
+		marked[i].MyVote = votes[id]
+		marked[i].MyReactions = reactions[id]
+	}
+	return marked
+}
+
+func (uc *blogUsecase) ResolveSlug(ctx context.Context, slug string) (string, string, error) {
+	blog, err := uc.blogRepo.GetBlogBySlug(ctx, slug)
+	if err != nil {
+		return "", "", errors.New("blog not found")
+	}
+	return blog.ID.Hex(), blog.Slug, nil
 }
 
 func (uc *blogUsecase) CreateBlog(ctx context.Context, blog domain.Blog, userID string) (*domain.Blog, error) {
@@ -68,16 +264,94 @@ func (uc *blogUsecase) CreateBlog(ctx context.Context, blog domain.Blog, userID
 	if userID == "" {
 		return nil, fmt.Errorf("user ID is required")
 	}
+	if blog.PublishAt != nil {
+		if !blog.PublishAt.After(time.Now()) {
+			return nil, fmt.Errorf("publish_at must be in the future")
+		}
+		blog.Status = domain.BlogStatusScheduled
+	}
+	if blog.Status == domain.BlogStatusScheduled && blog.PublishAt == nil {
+		return nil, fmt.Errorf("publish_at is required for a scheduled blog")
+	}
+	if blog.Status != "" && blog.Status != domain.BlogStatusDraft && blog.Status != domain.BlogStatusInReview &&
+		blog.Status != domain.BlogStatusScheduled && blog.Status != domain.BlogStatusPublished {
+		return nil, fmt.Errorf("a new blog can only be a draft, in review, scheduled or published")
+	}
+
+	html, err := uc.markdown.RenderBlog(blog.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render blog content: %w", err)
+	}
+	blog.ContentHTML = html
 
 	// Pass userID string to repository, let it handle ObjectID conversion
 	return uc.blogRepo.CreateBlog(ctx, blog, userID)
 }
 
+// blogEditAttempts is how often an edit is retried when a concurrent edit of the same blog came first
+const blogEditAttempts = 3
+
 func (uc *blogUsecase) UpdateBlog(ctx context.Context, id string, userID string, input domain.BlogUpdateInput) error {
-	if input.Title == "" && input.Content == "" && len(input.Tags) == 0 {
+	if input.Title == "" && input.Content == "" && len(input.Tags) == 0 && input.ModerateComments == nil {
 		return errors.New("nothing to update")
 	}
 
+	if input.Content != "" {
+		html, err := uc.markdown.RenderBlog(input.Content)
+		if err != nil {
+			return fmt.Errorf("failed to render blog content: %w", err)
+		}
+		input.ContentHTML = html
+	}
+
+	// an edit that lost the race against a concurrent one is retried on top of the version that won
+	for range blogEditAttempts {
+		blog, err := uc.blogRepo.GetBlogByID(ctx, id)
+		if err != nil {
+			return errors.New("blog not found")
+		}
+
+		if blog.UserID.Hex() != userID {
+			return errors.New("unauthorized access")
+		}
+
+		if err := uc.applyEdit(ctx, blog, userID, input); !errors.Is(err, domain.ErrRevisionConflict) {
+			return err
+		}
+	}
+	return domain.ErrRevisionConflict
+}
+
+// applyEdit saves the version being replaced as a revision and then applies the edit on top of exactly that version.
+// The revision is written first so an edit is never applied without one.
+func (uc *blogUsecase) applyEdit(ctx context.Context, blog *domain.Blog, userID string, input domain.BlogUpdateInput) error {
+	// settings such as comment moderation are not part of the text, so changing them alone makes no revision
+	var revision *domain.BlogRevision
+	if editsText(blog, input) {
+		var err error
+		if revision, err = uc.saveRevision(ctx, blog, userID); err != nil {
+			return err
+		}
+	}
+
+	err := uc.blogRepo.UpdateBlog(ctx, blog.ID.Hex(), userID, input, blog.Updated)
+	if errors.Is(err, domain.ErrRevisionConflict) && revision != nil {
+		// the concurrent edit that won already saved this version, so the copy made here is dropped
+		if err := uc.revisionRepo.DeleteRevision(ctx, blog.ID.Hex(), revision.ID.Hex()); err != nil {
+			log.Println("failed to delete revision of a conflicting edit of blog", blog.ID.Hex(), err)
+		}
+	}
+	return err
+}
+
+// editsText reports whether the update changes the title, content or tags of the blog
+func editsText(blog *domain.Blog, input domain.BlogUpdateInput) bool {
+	return (input.Title != "" && input.Title != blog.Title) ||
+		(input.Content != "" && input.Content != blog.Content) ||
+		(input.Tags != nil && !slices.Equal(input.Tags, blog.Tags))
+}
+
+func (uc *blogUsecase) UpdateBlogStatus(ctx context.Context, id string, userID string, input domain.BlogStatusInput) error {
 	blog, err := uc.blogRepo.GetBlogByID(ctx, id)
 	if err != nil {
 		return errors.New("blog not found")
@@ -87,7 +361,46 @@ func (uc *blogUsecase) UpdateBlog(ctx context.Context, id string, userID string,
 		return errors.New("unauthorized access")
 	}
 
-	return uc.blogRepo.UpdateBlog(ctx, id, userID, input)
+	return uc.changeStatus(ctx, blog, input)
+}
+
+func (uc *blogUsecase) UpdateBlogStatusAsAdmin(ctx context.Context, id string, input domain.BlogStatusInput) error {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, id)
+	if err != nil {
+		return errors.New("blog not found")
+	}
+
+	return uc.changeStatus(ctx, blog, input)
+}
+
+func (uc *blogUsecase) changeStatus(ctx context.Context, blog *domain.Blog, input domain.BlogStatusInput) error {
+	status := input.Status
+	if !validStatus[status] {
+		return errors.New("invalid status")
+	}
+
+	if status == domain.BlogStatusScheduled && (input.PublishAt == nil || !input.PublishAt.After(time.Now())) {
+		return errors.New("publish_at must be in the future")
+	}
+
+	current := blog.Status
+	if current == "" {
+		current = domain.BlogStatusPublished
+	}
+
+	if !statusTransitions[current][status] {
+		return errors.New("invalid status transition")
+	}
+
+	return uc.blogRepo.UpdateBlogStatus(ctx, blog.ID.Hex(), status, input.PublishAt)
+}
+
+func (uc *blogUsecase) PublishDueBlogs(ctx context.Context) (int, error) {
+	ids, err := uc.blogRepo.PublishDueBlogs(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
 }
 
 func (uc *blogUsecase) DeleteBlog(ctx context.Context, id string, userID string) error {
@@ -100,54 +413,255 @@ func (uc *blogUsecase) DeleteBlog(ctx context.Context, id string, userID string)
 		return errors.New("unauthorized access: only the blog author can delete this blog")
 	}
 
-	return uc.blogRepo.DeleteBlog(ctx, id)
+	return uc.DeleteBlogAsAdmin(ctx, id)
 }
 
 func (uc *blogUsecase) DeleteBlogAsAdmin(ctx context.Context, blogID string) error {
-	return uc.blogRepo.DeleteBlog(ctx, blogID)
+	if err := uc.blogRepo.DeleteBlog(ctx, blogID); err != nil {
+		return err
+	}
+
+	if err := uc.revisionRepo.DeleteRevisionsByBlogID(ctx, blogID); err != nil {
+		log.Println("failed to delete revisions of blog", blogID, err)
+	}
+	if err := uc.reactionRepo.DeleteReactionsByBlogID(ctx, blogID); err != nil {
+		log.Println("failed to delete reactions of blog", blogID, err)
+	}
+	if err := uc.voteRepo.DeleteVotesByBlogID(ctx, blogID); err != nil {
+		log.Println("failed to delete votes of blog", blogID, err)
+	}
+	if err := uc.analyticsRepo.DeleteByBlogID(ctx, blogID); err != nil {
+		log.Println("failed to delete analytics of blog", blogID, err)
+	}
+	return nil
 }
 
 func (uc *blogUsecase) LikeBlog(ctx context.Context, blogID string, userID string) error {
-	_, err := uc.blogRepo.GetBlogByID(ctx, blogID)
-	if err != nil {
-		return fmt.Errorf("blog not found: %w", err)
+	if err := uc.vote(ctx, blogID, userID, domain.VoteLike); err != nil {
+		return fmt.Errorf("failed to like: %w", err)
+	}
+	return nil
+}
+
+func (uc *blogUsecase) DislikeBlog(ctx context.Context, blogID string, userID string) error {
+	if err := uc.vote(ctx, blogID, userID, domain.VoteDislike); err != nil {
+		return fmt.Errorf("failed to dislike: %w", err)
+	}
+	return nil
+}
+
+// vote toggles the user's vote and applies the resulting change to the blog's counts
+func (uc *blogUsecase) vote(ctx context.Context, blogID string, userID string, voteType string) error {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil || !blog.IsPublished() {
+		return errors.New("blog not found")
 	}
-	err = uc.blogRepo.LikeBlog(ctx, blogID, userID)
+
+	likes, dislikes, err := uc.voteRepo.ToggleVote(ctx, blogID, userID, voteType)
 	if err != nil {
-		return fmt.Errorf("failed to like: %w", err)
+		return err
 	}
+	if err := uc.blogRepo.IncrementVotes(ctx, blogID, likes, dislikes); err != nil {
+		return err
+	}
+
+	uc.record(ctx, blog, domain.StatCounters{Likes: likes, Dislikes: dislikes})
 	uc.dispatcher.Enqueue(blogID)
 	return nil
 }
 
-func (uc *blogUsecase) DislikeBlog(ctx context.Context, blogID string, userID string) error {
+func (uc *blogUsecase) ReactToBlog(ctx context.Context, blogID string, userID string, reactionType string) (*domain.Reaction, error) {
+	if _, ok := uc.reactionWeights[reactionType]; !ok {
+		return nil, errors.New("invalid reaction")
+	}
 
-	_, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil || !blog.IsPublished() {
+		return nil, errors.New("blog not found")
+	}
+
+	reaction, err := uc.reactionRepo.AddReaction(ctx, blogID, userID, reactionType)
 	if err != nil {
-		return fmt.Errorf("blog not found: %w", err)
+		return nil, err
+	}
+	if err := uc.blogRepo.IncrementReaction(ctx, blogID, reactionType, 1); err != nil {
+		return nil, err
 	}
+	uc.record(ctx, blog, domain.StatCounters{Reactions: 1})
+
+	uc.dispatcher.Enqueue(blogID)
+	return reaction, nil
+}
 
-	err = uc.blogRepo.DislikeBlog(ctx, blogID, userID)
+func (uc *blogUsecase) RemoveReaction(ctx context.Context, blogID string, userID string, reactionType string) error {
+	if _, ok := uc.reactionWeights[reactionType]; !ok {
+		return errors.New("invalid reaction")
+	}
+
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
 	if err != nil {
-		return fmt.Errorf("failed to dislike: %w", err)
+		return errors.New("blog not found")
+	}
+
+	if err := uc.reactionRepo.RemoveReaction(ctx, blogID, userID, reactionType); err != nil {
+		return err
+	}
+	if err := uc.blogRepo.IncrementReaction(ctx, blogID, reactionType, -1); err != nil {
+		return err
 	}
+	uc.record(ctx, blog, domain.StatCounters{Reactions: -1})
+
 	uc.dispatcher.Enqueue(blogID)
 	return nil
 }
 
-func (uc *blogUsecase) RefreshPopularity(ctx context.Context, blogID string) error {
+// GetReactions lists who reacted to a blog, optionally only with one reaction type
+func (uc *blogUsecase) GetReactions(ctx context.Context, blogID string, reactionType string, page int, limit int) (*domain.PaginatedReactionResponse, error) {
+	if page < 1 || limit < 1 {
+		return nil, errors.New("invalid pagination params")
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if reactionType != "" {
+		if _, ok := uc.reactionWeights[reactionType]; !ok {
+			return nil, errors.New("invalid reaction")
+		}
+	}
+
 	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil || !blog.IsPublished() {
+		return nil, errors.New("blog not found")
+	}
+
+	reactions, totalCount, err := uc.reactionRepo.GetReactions(ctx, blogID, reactionType, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+
+	totalPages := int(math.Ceil(float64(totalCount) / float64(limit)))
+
+	return &domain.PaginatedReactionResponse{
+		Reactions:   reactions,
+		TotalCount:  totalCount,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+	}, nil
+}
+
+// RefreshPopularity recomputes the scores and comment counts of a batch of blogs with one query per collection.
+// Blogs that were deleted or unpublished since the refresh was asked for are skipped.
+func (uc *blogUsecase) RefreshPopularity(ctx context.Context, blogIDs []string) error {
+	blogs, err := uc.blogRepo.GetBlogsByIDs(ctx, blogIDs)
+	if err != nil {
+		return fmt.Errorf("failed to fetch blogs: %w", err)
+	}
+	if len(blogs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(blogs))
+	for i, blog := range blogs {
+		ids[i] = blog.ID.Hex()
+	}
+
+	comments, err := uc.commentRepo.CountCommentsByBlogIDs(ctx, ids)
 	if err != nil {
-		return fmt.Errorf("failed to fetch blog: %w", err)
+		return err
 	}
 
-	counts, err := uc.commentRepo.CountCommentsByBlogID(ctx, blogID)
+	now := time.Now()
+	series, err := uc.analyticsRepo.GetSeriesByBlogIDs(ctx, ids, now.Add(-uc.trending.Window), now)
 	if err != nil {
 		return err
 	}
 
-	score := CalculateScore(blog.ViewCount, blog.Likes, blog.Dislikes, counts)
-	return uc.blogRepo.UpdateStats(ctx, blogID, score, counts)
+	stats := make([]domain.BlogStats, len(blogs))
+	for i := range blogs {
+		stats[i] = domain.BlogStats{
+			BlogID:          ids[i],
+			PopularityScore: uc.scoring.Score(scoreInput(&blogs[i], comments[ids[i]]), now),
+			TrendingScore:   TrendingScore(series[ids[i]], uc.trending),
+			CommentCount:    comments[ids[i]],
+		}
+	}
+	return uc.blogRepo.UpdateStats(ctx, stats)
+}
+
+// scoreRecomputeBatch is how many blogs are rescored at a time
+const scoreRecomputeBatch = 500
+
+// StartScoreRecompute rescores every blog with the configured strategy in the background,
+// for example after the strategy or its weights changed
+func (uc *blogUsecase) StartScoreRecompute() error {
+	if !uc.recomputing.CompareAndSwap(false, true) {
+		return errors.New("score recompute already running")
+	}
+
+	go func() {
+		defer uc.recomputing.Store(false)
+
+		rescored, err := uc.recomputeScores(context.Background())
+		if err != nil {
+			log.Printf("Score recompute failed after %d blogs: %v", rescored, err)
+			return
+		}
+		log.Printf("Recomputed the popularity score of %d blogs", rescored)
+	}()
+
+	return nil
+}
+
+func (uc *blogUsecase) recomputeScores(ctx context.Context) (int, error) {
+	rescored := 0
+	afterID := ""
+	for {
+		blogs, err := uc.blogRepo.GetBlogsAfter(ctx, afterID, scoreRecomputeBatch)
+		if err != nil {
+			return rescored, err
+		}
+		if len(blogs) == 0 {
+			return rescored, nil
+		}
+
+		ids := make([]string, len(blogs))
+		for i, blog := range blogs {
+			ids[i] = blog.ID.Hex()
+		}
+		comments, err := uc.commentRepo.CountCommentsByBlogIDs(ctx, ids)
+		if err != nil {
+			return rescored, err
+		}
+
+		now := time.Now()
+		scores := make(map[string]float64, len(blogs))
+		for i := range blogs {
+			scores[ids[i]] = uc.scoring.Score(scoreInput(&blogs[i], comments[ids[i]]), now)
+		}
+		if err := uc.blogRepo.UpdatePopularityScores(ctx, scores); err != nil {
+			return rescored, err
+		}
+
+		rescored += len(blogs)
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// scoreInput ages a blog from when it went live; blogs published before that was recorded age from their creation
+func scoreInput(blog *domain.Blog, comments int) domain.ScoreInput {
+	published := blog.Created
+	if blog.PublishedAt != nil {
+		published = *blog.PublishedAt
+	}
+
+	return domain.ScoreInput{
+		UniqueViews: blog.UniqueViews(),
+		Likes:       blog.Likes,
+		Dislikes:    blog.Dislikes,
+		Comments:    comments,
+		Reactions:   blog.Reactions,
+		Published:   published,
+	}
 }
 
 func (uc *blogUsecase) FilterBlogs(ctx context.Context, tags []string, startDate, endDate *time.Time, sortBy string, page int, limit int) (*domain.PaginatedBlogResponse, error) {
@@ -156,7 +670,7 @@ func (uc *blogUsecase) FilterBlogs(ctx context.Context, tags []string, startDate
 		return nil, errors.New("toDate cannot be before fromDate")
 	}
 
-	validSort := map[string]bool{"popular": true, "oldest": true, "": true}
+	validSort := map[string]bool{"popular": true, "trending": true, "oldest": true, "": true}
 	if !validSort[sortBy] {
 		return nil, errors.New("invalid sort format ")
 	}
@@ -165,7 +679,7 @@ func (uc *blogUsecase) FilterBlogs(ctx context.Context, tags []string, startDate
 		limit = 100
 	}
 
-	blogs, totalCount, err := uc.blogRepo.FilterBlogs(ctx, startDate, endDate, tags, sortBy, page, limit)
+	blogs, totalCount, err := uc.blogRepo.FilterBlogs(ctx, "", startDate, endDate, tags, sortBy, page, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blogs: %w", err)
 	}
@@ -183,8 +697,42 @@ func (uc *blogUsecase) FilterBlogs(ctx context.Context, tags []string, startDate
 
 //Helper function
 
-func CalculateScore(views, likes, dislikes, comments int) float64 {
-	return float64(views)*0.5 + float64(likes)*2 - float64(dislikes)*1 + float64(comments)*1.5
+// trendingEpoch is the fixed point in time trending scores are measured from
+var trendingEpoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// TrendingScore ranks a blog by its hourly activity, where each half-life that has passed halves what the activity is worth.
+// Instead of decaying older activity towards now, newer activity is grown by 2^(time since trendingEpoch / half-life)
+// and the score is the log2 of the sum. Both give the same order, but this way a stored score stays comparable with
+// scores computed later, so a blog that went quiet sinks below newer activity without being recomputed.
+// Blogs without any positive activity score 0.
+func TrendingScore(buckets []domain.StatBucket, policy TrendingPolicy) float64 {
+	if len(buckets) == 0 {
+		return 0
+	}
+
+	// the sum is taken relative to the newest bucket so the exponents cannot overflow
+	exponents := make([]float64, len(buckets))
+	newest := math.Inf(-1)
+	for i, b := range buckets {
+		exponents[i] = float64(b.Bucket.Sub(trendingEpoch)) / float64(policy.HalfLife)
+		newest = math.Max(newest, exponents[i])
+	}
+
+	w := policy.Weights
+	sum := 0.0
+	for i, b := range buckets {
+		activity := float64(b.UniqueViews)*w.UniqueViews +
+			float64(b.Likes)*w.Likes -
+			float64(b.Dislikes)*w.Dislikes +
+			float64(b.Comments)*w.Comments +
+			float64(b.Reactions)*w.Reactions
+		sum += activity * math.Exp2(exponents[i]-newest)
+	}
+	if sum <= 0 {
+		return 0
+	}
+
+	return math.Max(newest+math.Log2(sum), 0)
 }
 
 func (uc *blogUsecase) SearchBlogs(ctx context.Context, query string, page, limit int) (*domain.PaginatedBlogResponse, error) {
@@ -207,3 +755,33 @@ func (uc *blogUsecase) SearchBlogs(ctx context.Context, query string, page, limi
 	}, nil
 
 }
+
+// botMarkers are user agent fragments of crawlers, link previews and scripted clients
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "mediapartners", "facebookexternalhit", "embedly",
+	"preview", "headless", "lighthouse", "curl", "wget", "python-requests", "go-http-client",
+}
+
+// isBot reports whether a request comes from an automated client; requests without a user agent count as bots
+func isBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// visitorKey identifies a viewer for view deduplication: logged-in users by their ID,
+// anonymous visitors by a hash of their IP address and user agent
+func visitorKey(viewer domain.Viewer) string {
+	if viewer.UserID != "" {
+		return "user:" + viewer.UserID
+	}
+	sum := sha256.Sum256([]byte(viewer.IP + "|" + viewer.UserAgent))
+	return "anon:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+func TestScoreInput(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	publishedAt := created.Add(21 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		blog          domain.Blog
+		wantPublished time.Time
+	}{
+		{
+			name:          "draft published weeks after it was written ages from going live",
+			blog:          domain.Blog{Created: created, PublishedAt: &publishedAt},
+			wantPublished: publishedAt,
+		},
+		{
+			name:          "blog published before the publish time was recorded ages from its creation",
+			blog:          domain.Blog{Created: created},
+			wantPublished: created,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreInput(&tt.blog, 0).Published; !got.Equal(tt.wantPublished) {
+				t.Fatalf("scoreInput().Published = %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func TestScoreInputActivity(t *testing.T) {
+	blog := &domain.Blog{
+		ViewCount:       40,
+		UniqueViewCount: 25,
+		Likes:           7,
+		Dislikes:        2,
+		Reactions:       map[string]int{"love": 3},
+	}
+
+	got := scoreInput(blog, 5)
+	if got.UniqueViews != 25 || got.Likes != 7 || got.Dislikes != 2 || got.Comments != 5 || got.Reactions["love"] != 3 {
+		t.Fatalf("scoreInput() = %+v", got)
+	}
+
+	// blogs viewed only before unique views were counted fall back to their raw view count
+	blog.UniqueViewCount = 0
+	if got := scoreInput(blog, 0).UniqueViews; got != 40 {
+		t.Fatalf("scoreInput().UniqueViews = %d, want 40", got)
+	}
+}
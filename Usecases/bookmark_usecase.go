@@ -0,0 +1,319 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SharedListLimit caps how many bookmarks a shared reading list shows
+const SharedListLimit = 100
+
+type bookmarkUsecase struct {
+	bookmarkRepo domain.BookmarkRepository
+	blogRepo     domain.BlogRepository
+	baseURL      string
+}
+
+func NewBookmarkUsecase(bookmarkRepo domain.BookmarkRepository, blogRepo domain.BlogRepository, baseURL string) domain.BookmarkUsecase {
+	return &bookmarkUsecase{
+		bookmarkRepo: bookmarkRepo,
+		blogRepo:     blogRepo,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (uc *bookmarkUsecase) AddBookmark(ctx context.Context, userID string, input domain.BookmarkInput) (*domain.Bookmark, error) {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, input.BlogID)
+	if err != nil || !blog.IsPublished() {
+		return nil, errors.New("blog not found")
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	bookmark := domain.Bookmark{
+		UserID:  userObjID,
+		BlogID:  blog.ID,
+		Created: time.Now(),
+	}
+
+	if input.ListID != "" {
+		list, err := uc.bookmarkRepo.GetReadingListByID(ctx, userID, input.ListID)
+		if err != nil {
+			return nil, err
+		}
+		position, err := uc.bookmarkRepo.NextPosition(ctx, userID, input.ListID)
+		if err != nil {
+			return nil, err
+		}
+		bookmark.ListID = &list.ID
+		bookmark.Position = position
+	}
+
+	created, err := uc.bookmarkRepo.CreateBookmark(ctx, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	created.Blog = blog
+	return created, nil
+}
+
+func (uc *bookmarkUsecase) GetBookmarks(ctx context.Context, userID string, listID string, unreadOnly bool, page int, limit int) (*domain.PaginatedBookmarkResponse, error) {
+	if page < 1 || limit < 1 {
+		return nil, errors.New("invalid pagination params")
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	if listID != "" {
+		if _, err := uc.bookmarkRepo.GetReadingListByID(ctx, userID, listID); err != nil {
+			return nil, err
+		}
+	}
+
+	bookmarks, totalCount, err := uc.bookmarkRepo.GetBookmarks(ctx, userID, listID, unreadOnly, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+	if err := uc.attachBlogs(ctx, bookmarks); err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(totalCount) / float64(limit)))
+
+	return &domain.PaginatedBookmarkResponse{
+		Bookmarks:   bookmarks,
+		TotalCount:  totalCount,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+	}, nil
+}
+
+func (uc *bookmarkUsecase) UpdateBookmark(ctx context.Context, userID string, id string, input domain.BookmarkUpdateInput) error {
+	if input.ListID == nil && input.Read == nil {
+		return errors.New("nothing to update")
+	}
+
+	bookmark, err := uc.bookmarkRepo.GetBookmarkByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if input.ListID != nil {
+		listID := *input.ListID
+		current := ""
+		if bookmark.ListID != nil {
+			current = bookmark.ListID.Hex()
+		}
+
+		if listID != current {
+			// a bookmark moved into a list goes to the end of it
+			position := 0
+			if listID != "" {
+				if _, err := uc.bookmarkRepo.GetReadingListByID(ctx, userID, listID); err != nil {
+					return err
+				}
+				position, err = uc.bookmarkRepo.NextPosition(ctx, userID, listID)
+				if err != nil {
+					return err
+				}
+			}
+			if err := uc.bookmarkRepo.MoveBookmark(ctx, userID, id, listID, position); err != nil {
+				return err
+			}
+		}
+	}
+
+	if input.Read != nil {
+		return uc.bookmarkRepo.SetBookmarkRead(ctx, userID, id, *input.Read)
+	}
+	return nil
+}
+
+func (uc *bookmarkUsecase) RemoveBookmark(ctx context.Context, userID string, id string) error {
+	return uc.bookmarkRepo.DeleteBookmark(ctx, userID, id)
+}
+
+func (uc *bookmarkUsecase) CreateReadingList(ctx context.Context, userID string, input domain.ReadingListInput) (*domain.ReadingList, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	now := time.Now()
+	list := domain.ReadingList{
+		UserID:      userObjID,
+		Name:        name,
+		Description: strings.TrimSpace(input.Description),
+		Created:     now,
+		Updated:     now,
+	}
+	return uc.bookmarkRepo.CreateReadingList(ctx, list)
+}
+
+func (uc *bookmarkUsecase) GetReadingLists(ctx context.Context, userID string) ([]domain.ReadingList, error) {
+	lists, err := uc.bookmarkRepo.GetReadingLists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lists {
+		lists[i].ShareURL = uc.shareURL(&lists[i])
+	}
+	return lists, nil
+}
+
+func (uc *bookmarkUsecase) UpdateReadingList(ctx context.Context, userID string, id string, input domain.ReadingListInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Description = strings.TrimSpace(input.Description)
+	if input.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	return uc.bookmarkRepo.UpdateReadingList(ctx, userID, id, input)
+}
+
+func (uc *bookmarkUsecase) DeleteReadingList(ctx context.Context, userID string, id string) error {
+	return uc.bookmarkRepo.DeleteReadingList(ctx, userID, id)
+}
+
+// ReorderReadingList applies a new order to a reading list.
+// Every bookmark of the list has to be passed exactly once.
+func (uc *bookmarkUsecase) ReorderReadingList(ctx context.Context, userID string, id string, input domain.ReorderInput) error {
+	if _, err := uc.bookmarkRepo.GetReadingListByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	current, err := uc.bookmarkRepo.GetListBookmarkIDs(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	inList := make(map[string]bool, len(current))
+	for _, bookmarkID := range current {
+		inList[bookmarkID] = true
+	}
+	if len(input.BookmarkIDs) != len(current) {
+		return errors.New("bookmark ids must match the reading list")
+	}
+	for _, bookmarkID := range input.BookmarkIDs {
+		if !inList[bookmarkID] {
+			return errors.New("bookmark ids must match the reading list")
+		}
+		delete(inList, bookmarkID) // catches duplicates
+	}
+
+	return uc.bookmarkRepo.SetBookmarkPositions(ctx, userID, input.BookmarkIDs)
+}
+
+func (uc *bookmarkUsecase) ShareReadingList(ctx context.Context, userID string, id string) (*domain.ReadingList, error) {
+	list, err := uc.bookmarkRepo.GetReadingListByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.ShareToken == "" {
+		token, err := shareToken()
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.bookmarkRepo.SetShareToken(ctx, userID, id, token); err != nil {
+			return nil, err
+		}
+		list.ShareToken = token
+	}
+
+	list.ShareURL = uc.shareURL(list)
+	return list, nil
+}
+
+func (uc *bookmarkUsecase) UnshareReadingList(ctx context.Context, userID string, id string) error {
+	return uc.bookmarkRepo.SetShareToken(ctx, userID, id, "")
+}
+
+// GetSharedReadingList returns a shared list with its published blogs, in the list's order
+func (uc *bookmarkUsecase) GetSharedReadingList(ctx context.Context, token string) (*domain.ReadingList, []domain.Bookmark, error) {
+	list, err := uc.bookmarkRepo.GetReadingListByShareToken(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bookmarks, _, err := uc.bookmarkRepo.GetBookmarks(ctx, list.UserID.Hex(), list.ID.Hex(), false, 1, SharedListLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+	if err := uc.attachBlogs(ctx, bookmarks); err != nil {
+		return nil, nil, err
+	}
+
+	// only blogs that are still public are shown, without the owner's reading progress
+	shared := make([]domain.Bookmark, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		if b.Blog == nil {
+			continue
+		}
+		b.Read = false
+		b.ReadAt = nil
+		shared = append(shared, b)
+	}
+
+	list.ShareURL = uc.shareURL(list)
+	return list, shared, nil
+}
+
+// attachBlogs fills in the blog of each bookmark; bookmarks of blogs that are no longer public keep a nil blog
+func (uc *bookmarkUsecase) attachBlogs(ctx context.Context, bookmarks []domain.Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		ids = append(ids, b.BlogID.Hex())
+	}
+
+	blogs, err := uc.blogRepo.GetBlogsByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get bookmarked blogs: %w", err)
+	}
+
+	byID := make(map[primitive.ObjectID]*domain.Blog, len(blogs))
+	for i := range blogs {
+		byID[blogs[i].ID] = &blogs[i]
+	}
+	for i := range bookmarks {
+		bookmarks[i].Blog = byID[bookmarks[i].BlogID]
+	}
+	return nil
+}
+
+func (uc *bookmarkUsecase) shareURL(list *domain.ReadingList) string {
+	if list.ShareToken == "" {
+		return ""
+	}
+	return uc.baseURL + "/shared/reading-lists/" + list.ShareToken
+}
+
+// shareToken returns a random, unguessable token for a reading list's public link
+func shareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
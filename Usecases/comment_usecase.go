@@ -4,20 +4,46 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Comment moderation modes
+const (
+	ModerationOff       = "off"       // only blogs with moderate_comments hold comments from untrusted users
+	ModerationUntrusted = "untrusted" // comments from untrusted users are held on every blog
+	ModerationAll       = "all"       // every comment is held unless it comes from the post author or an admin
+)
+
+// CommentPolicy holds the site-wide comment settings
+type CommentPolicy struct {
+	MaxDepth     int // deepest reply level allowed; top-level comments are depth 0
+	Moderation   string
+	TrustedAfter int // approved comments a user needs before their comments skip moderation
+}
+
 type commentUsecase struct {
-	commentRepo domain.CommentRepository
-	dispatcher  domain.BlogRefreshDispatcher
+	commentRepo   domain.CommentRepository
+	blogRepo      domain.BlogRepository
+	userRepo      domain.IUserRepository
+	analyticsRepo domain.AnalyticsRepository
+	dispatcher    domain.BlogRefreshDispatcher
+	markdown      domain.IMarkdownService
+	policy        CommentPolicy
 }
 
-func NewCommentUsecase(repo domain.CommentRepository, dispatcher domain.BlogRefreshDispatcher) *commentUsecase {
+func NewCommentUsecase(repo domain.CommentRepository, blogRepo domain.BlogRepository, userRepo domain.IUserRepository, analyticsRepo domain.AnalyticsRepository, dispatcher domain.BlogRefreshDispatcher, markdown domain.IMarkdownService, policy CommentPolicy) *commentUsecase {
 	return &commentUsecase{
-		commentRepo: repo,
-		dispatcher:  dispatcher,
+		commentRepo:   repo,
+		blogRepo:      blogRepo,
+		userRepo:      userRepo,
+		analyticsRepo: analyticsRepo,
+		dispatcher:    dispatcher,
+		markdown:      markdown,
+		policy:        policy,
 	}
 }
 
@@ -29,16 +55,88 @@ func (uc *commentUsecase) CreateComment(ctx context.Context, blogID string, user
 		return nil, errors.New("message is too long (max 500 chars)")
 	}
 
+	html, err := uc.markdown.RenderComment(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render comment: %w", err)
+	}
+
 	comment := domain.Comment{
-		Message: message,
-		Created: time.Now(),
-		Updated: time.Now(),
+		Message:     message,
+		MessageHTML: html,
+		Created:     time.Now(),
+		Updated:     time.Now(),
 	}
-	uc.dispatcher.Enqueue(blogID)
-	return uc.commentRepo.CreateComment(ctx, blogID, userID, comment)
+	return uc.saveComment(ctx, blogID, userID, comment)
 }
 
-func (uc *commentUsecase) GetAllComments(ctx context.Context, blogID string, page int, limit int, sort string) ([]*domain.Comment, int, error) {
+// saveComment decides whether a new comment needs moderation and stores it
+func (uc *commentUsecase) saveComment(ctx context.Context, blogID string, userID string, comment domain.Comment) (*domain.Comment, error) {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil || !blog.IsPublished() {
+		return nil, errors.New("blog not found")
+	}
+
+	status, err := uc.initialStatus(ctx, blog, userID)
+	if err != nil {
+		return nil, err
+	}
+	comment.BlogAuthorID = blog.UserID
+	comment.Status = status
+
+	created, err := uc.commentRepo.CreateComment(ctx, blogID, userID, comment)
+	if err != nil {
+		return nil, err
+	}
+	if created.IsApproved() {
+		uc.countComment(ctx, created)
+		uc.dispatcher.Enqueue(blogID)
+	}
+	return created, nil
+}
+
+// checkReadable hides the comments of unpublished blogs from everyone but the blog's author
+func (uc *commentUsecase) checkReadable(ctx context.Context, blogID string, viewerID string) error {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil || (!blog.IsPublished() && blog.UserID.Hex() != viewerID) {
+		return errors.New("blog not found")
+	}
+	return nil
+}
+
+// countComment adds a comment that became visible to its blog's analytics
+func (uc *commentUsecase) countComment(ctx context.Context, comment *domain.Comment) {
+	err := uc.analyticsRepo.Record(ctx, comment.BlogID.Hex(), comment.BlogAuthorID.Hex(), time.Now(), domain.StatCounters{Comments: 1})
+	if err != nil {
+		log.Println("failed to record comment of blog", comment.BlogID.Hex(), err)
+	}
+}
+
+// initialStatus holds comments from untrusted users when the site or the blog moderates comments.
+// Post authors and admins are always trusted; other users are trusted once they have enough approved comments.
+func (uc *commentUsecase) initialStatus(ctx context.Context, blog *domain.Blog, userID string) (string, error) {
+	if blog.UserID.Hex() == userID {
+		return domain.CommentStatusApproved, nil
+	}
+	if user, err := uc.userRepo.Get(ctx, userID); err == nil && user.Role == "admin" {
+		return domain.CommentStatusApproved, nil
+	}
+
+	switch {
+	case uc.policy.Moderation == ModerationAll:
+		return domain.CommentStatusPending, nil
+	case uc.policy.Moderation == ModerationUntrusted || blog.ModerateComments:
+		approved, err := uc.commentRepo.CountApprovedCommentsByUser(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if approved < uc.policy.TrustedAfter {
+			return domain.CommentStatusPending, nil
+		}
+	}
+	return domain.CommentStatusApproved, nil
+}
+
+func (uc *commentUsecase) GetAllComments(ctx context.Context, blogID string, viewerID string, page int, limit int, sort string) ([]*domain.Comment, int, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -46,15 +144,82 @@ func (uc *commentUsecase) GetAllComments(ctx context.Context, blogID string, pag
 		limit = 10
 	}
 
-	comments, total, err := uc.commentRepo.GetAllComments(ctx, blogID, page, limit, sort)
+	if err := uc.checkReadable(ctx, blogID, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	comments, total, err := uc.commentRepo.GetAllComments(ctx, blogID, viewerID, page, limit, sort)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to retrieve comments: %w", err)
 	}
 	return comments, total, nil
 }
 
-func (uc *commentUsecase) GetCommentByID(ctx context.Context, blogID string, commentID string) (*domain.Comment, error) {
-	return uc.commentRepo.GetCommentByID(ctx, blogID, commentID)
+func (uc *commentUsecase) CreateReply(ctx context.Context, blogID string, parentID string, userID string, message string) (*domain.Comment, error) {
+	if len(message) == 0 {
+		return nil, errors.New("message cannot be empty")
+	}
+	if len(message) > 500 {
+		return nil, errors.New("message is too long (max 500 chars)")
+	}
+
+	parent, err := uc.commentRepo.GetCommentByID(ctx, blogID, parentID)
+	if err != nil || parent.Deleted || parent.Hidden || !parent.IsApproved() {
+		return nil, errors.New("comment not found")
+	}
+	if parent.Depth+1 > uc.policy.MaxDepth {
+		return nil, errors.New("maximum reply depth reached")
+	}
+
+	html, err := uc.markdown.RenderComment(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render comment: %w", err)
+	}
+
+	reply := domain.Comment{
+		Message:     message,
+		MessageHTML: html,
+		ParentID:    &parent.ID,
+		Depth:       parent.Depth + 1,
+		Created:     time.Now(),
+		Updated:     time.Now(),
+	}
+	return uc.saveComment(ctx, blogID, userID, reply)
+}
+
+func (uc *commentUsecase) GetReplies(ctx context.Context, blogID string, parentID string, viewerID string, page int, limit int, sort string) ([]*domain.Comment, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	if err := uc.checkReadable(ctx, blogID, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	replies, total, err := uc.commentRepo.GetReplies(ctx, blogID, parentID, viewerID, page, limit, sort)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve replies: %w", err)
+	}
+	return replies, total, nil
+}
+
+func (uc *commentUsecase) GetCommentByID(ctx context.Context, blogID string, commentID string, viewerID string) (*domain.Comment, error) {
+	if err := uc.checkReadable(ctx, blogID, viewerID); err != nil {
+		return nil, err
+	}
+
+	comment, err := uc.commentRepo.GetCommentByID(ctx, blogID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	// comments awaiting moderation or hidden after reports are only visible to the commenter
+	if (!comment.IsApproved() || comment.Hidden) && comment.UserID.Hex() != viewerID {
+		return nil, errors.New("comment not found")
+	}
+	return comment, nil
 }
 
 func (uc *commentUsecase) EditComment(ctx context.Context, blogID string, commentID string, userID string, message string) error {
@@ -65,7 +230,12 @@ func (uc *commentUsecase) EditComment(ctx context.Context, blogID string, commen
 		return errors.New("message is too long (max 500 chars)")
 	}
 
-	return uc.commentRepo.EditComment(ctx, blogID, commentID, userID, message)
+	html, err := uc.markdown.RenderComment(message)
+	if err != nil {
+		return fmt.Errorf("failed to render comment: %w", err)
+	}
+
+	return uc.commentRepo.EditComment(ctx, blogID, commentID, userID, message, html)
 }
 
 func (uc *commentUsecase) DeleteComment(ctx context.Context, blogID, commentID, userID string) error {
@@ -102,3 +272,68 @@ func (uc *commentUsecase) DeleteCommentAsAdmin(ctx context.Context, blogID, comm
 
 	return nil
 }
+
+func (uc *commentUsecase) GetModerationQueue(ctx context.Context, userID string, page int, limit int) ([]*domain.Comment, int, error) {
+	return uc.moderationQueue(ctx, userID, page, limit)
+}
+
+func (uc *commentUsecase) GetModerationQueueAsAdmin(ctx context.Context, page int, limit int) ([]*domain.Comment, int, error) {
+	// Admin sees pending comments on every blog
+	return uc.moderationQueue(ctx, "", page, limit)
+}
+
+func (uc *commentUsecase) moderationQueue(ctx context.Context, blogAuthorID string, page int, limit int) ([]*domain.Comment, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	comments, total, err := uc.commentRepo.GetPendingComments(ctx, blogAuthorID, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve moderation queue: %w", err)
+	}
+	return comments, total, nil
+}
+
+func (uc *commentUsecase) ModerateComments(ctx context.Context, userID string, input domain.ModerationInput) (int, error) {
+	return uc.moderate(ctx, userID, input)
+}
+
+func (uc *commentUsecase) ModerateCommentsAsAdmin(ctx context.Context, input domain.ModerationInput) (int, error) {
+	// Admin can moderate comments on any blog
+	return uc.moderate(ctx, "", input)
+}
+
+func (uc *commentUsecase) moderate(ctx context.Context, blogAuthorID string, input domain.ModerationInput) (int, error) {
+	var status string
+	switch input.Action {
+	case "approve":
+		status = domain.CommentStatusApproved
+	case "reject":
+		status = domain.CommentStatusRejected
+	default:
+		return 0, errors.New("invalid moderation action")
+	}
+	for _, id := range input.CommentIDs {
+		if !primitive.IsValidObjectID(id) {
+			return 0, errors.New("invalid comment ID")
+		}
+	}
+
+	moderated, err := uc.commentRepo.SetCommentsStatus(ctx, input.CommentIDs, blogAuthorID, status)
+	if status == domain.CommentStatusApproved {
+		// approved comments change the comment counts of their blogs
+		refreshed := make(map[string]bool)
+		for _, comment := range moderated {
+			uc.countComment(ctx, comment)
+			blogID := comment.BlogID.Hex()
+			if !refreshed[blogID] {
+				refreshed[blogID] = true
+				uc.dispatcher.Enqueue(blogID)
+			}
+		}
+	}
+	return len(moderated), err
+}
@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+// FeedSize is the number of latest published blogs included in a feed
+const FeedSize = 50
+
+type feedUsecase struct {
+	blogRepo  domain.BlogRepository
+	userRepo  domain.IUserRepository
+	feedCache domain.SortedCache[*domain.Feed]
+	baseURL   string
+}
+
+func NewFeedUsecase(blogRepo domain.BlogRepository, userRepo domain.IUserRepository, feedCache domain.SortedCache[*domain.Feed], baseURL string) domain.FeedUsecase {
+	return &feedUsecase{
+		blogRepo:  blogRepo,
+		userRepo:  userRepo,
+		feedCache: feedCache,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (uc *feedUsecase) SiteFeed(ctx context.Context) (*domain.Feed, error) {
+	return uc.buildFeed(ctx, "feed:site", "", nil, domain.Feed{
+		ID:          uc.baseURL + "/feeds",
+		Title:       "Latest blogs",
+		Description: "The latest published blogs",
+		Link:        uc.baseURL + "/blogs",
+	})
+}
+
+func (uc *feedUsecase) AuthorFeed(ctx context.Context, userID string) (*domain.Feed, error) {
+	if cached, found := uc.feedCache.Get("feed:author:" + userID); found {
+		return cached, nil
+	}
+
+	user, err := uc.userRepo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrInvalidUserID) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	name := strings.TrimSpace(user.Firstname + " " + user.Lastname)
+	return uc.buildFeed(ctx, "feed:author:"+userID, userID, nil, domain.Feed{
+		ID:          uc.baseURL + "/feeds/authors/" + userID,
+		Title:       "Blogs by " + name,
+		Description: "The latest published blogs by " + name,
+		Link:        uc.baseURL + "/blogs",
+	})
+}
+
+func (uc *feedUsecase) TagFeed(ctx context.Context, tag string) (*domain.Feed, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return nil, errors.New("tag is required")
+	}
+
+	// the tag comes from the request, so it is escaped before it becomes part of a URL
+	return uc.buildFeed(ctx, "feed:tag:"+tag, "", []string{tag}, domain.Feed{
+		ID:          uc.baseURL + "/feeds/tags/" + url.PathEscape(tag),
+		Title:       "Blogs tagged " + tag,
+		Description: "The latest published blogs tagged " + tag,
+		Link:        uc.baseURL + "/blogs/filter?tags=" + url.QueryEscape(tag),
+	})
+}
+
+// buildFeed fills the feed with the latest published blogs matching the author and tags,
+// serving it from the feed cache until a blog is created, updated or deleted
+func (uc *feedUsecase) buildFeed(ctx context.Context, cacheKey string, authorID string, tags []string, feed domain.Feed) (*domain.Feed, error) {
+	if cached, found := uc.feedCache.Get(cacheKey); found {
+		log.Println("cache hit for feed:", cacheKey)
+		return cached, nil
+	}
+
+	// blogs are ordered by when they went live, so a draft published today is not buried under older posts
+	blogs, _, err := uc.blogRepo.FilterBlogs(ctx, authorID, nil, nil, tags, "published", 1, FeedSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blogs: %w", err)
+	}
+
+	feed.Items = make([]domain.FeedItem, 0, len(blogs))
+	for _, blog := range blogs {
+		published := blog.Created
+		if blog.PublishedAt != nil {
+			published = *blog.PublishedAt
+		}
+
+		feed.Items = append(feed.Items, domain.FeedItem{
+			ID:        uc.baseURL + "/blogs/" + blog.ID.Hex(),
+			Title:     blog.Title,
+			Link:      uc.blogLink(blog),
+			Author:    blog.AuthorName,
+			Summary:   blog.ContentHTML,
+			Tags:      blog.Tags,
+			Published: published,
+			Updated:   blog.Updated,
+		})
+
+		if blog.Updated.After(feed.Updated) {
+			feed.Updated = blog.Updated
+		}
+	}
+
+	feed.Modified = time.Now()
+	uc.feedCache.SetWithSortKey(domain.FeedCacheGroup, cacheKey, &feed)
+	return &feed, nil
+}
+
+func (uc *feedUsecase) blogLink(blog domain.Blog) string {
+	if blog.Slug != "" {
+		return uc.baseURL + "/blogs/by-slug/" + blog.Slug
+	}
+	return uc.baseURL + "/blogs/" + blog.ID.Hex()
+}
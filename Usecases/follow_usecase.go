@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"context"
+	"math"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+type FollowUsecases struct {
+	followRepo domain.IFollowRepository
+	userRepo   domain.IUserRepository
+	blogRepo   domain.BlogRepository
+}
+
+func NewFollowUsecase(followRepo domain.IFollowRepository, userRepo domain.IUserRepository, blogRepo domain.BlogRepository) *FollowUsecases {
+	return &FollowUsecases{
+		followRepo: followRepo,
+		userRepo:   userRepo,
+		blogRepo:   blogRepo,
+	}
+}
+
+func (f *FollowUsecases) Follow(ctx context.Context, followerID, followeeID string) error {
+
+	if followerID == followeeID {
+		return domain.ErrCannotFollowSelf
+	}
+
+	// make sure the author exists before following them
+	if _, err := f.userRepo.Get(ctx, followeeID); err != nil {
+		return err
+	}
+
+	return f.followRepo.Follow(ctx, followerID, followeeID)
+}
+
+func (f *FollowUsecases) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	return f.followRepo.Unfollow(ctx, followerID, followeeID)
+}
+
+// HomeFeed returns the published blogs of the authors the user follows, newest first
+func (f *FollowUsecases) HomeFeed(ctx context.Context, userID string, page, limit int) (*domain.PaginatedBlogResponse, error) {
+
+	if page < 1 || limit < 1 {
+		return nil, domain.ErrBadRequest
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	authorIDs, err := f.followRepo.GetFollowingIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.PaginatedBlogResponse{
+		Blogs:       []domain.Blog{},
+		CurrentPage: page,
+	}
+	if len(authorIDs) == 0 {
+		return response, nil
+	}
+
+	blogs, totalCount, err := f.blogRepo.GetBlogsByAuthors(ctx, authorIDs, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if blogs != nil {
+		response.Blogs = blogs
+	}
+	response.TotalCount = totalCount
+	response.TotalPages = int(math.Ceil(float64(totalCount) / float64(limit)))
+
+	return response, nil
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+var validJobStatus = map[string]bool{
+	domain.JobStatusPending: true,
+	domain.JobStatusRunning: true,
+	domain.JobStatusDead:    true,
+}
+
+type jobUsecase struct {
+	jobRepo    domain.JobRepository
+	dispatcher domain.BlogRefreshDispatcher
+}
+
+func NewJobUsecase(jobRepo domain.JobRepository, dispatcher domain.BlogRefreshDispatcher) domain.JobUsecase {
+	return &jobUsecase{
+		jobRepo:    jobRepo,
+		dispatcher: dispatcher,
+	}
+}
+
+// GetJobs lists queued jobs for admins, optionally of one status
+func (uc *jobUsecase) GetJobs(ctx context.Context, status string, page int, limit int) ([]domain.Job, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, errors.New("invalid pagination params")
+	}
+	if status != "" && !validJobStatus[status] {
+		return nil, 0, errors.New("invalid status")
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	return uc.jobRepo.GetJobs(ctx, status, page, limit)
+}
+
+// RequeueJob retries a dead job from scratch
+func (uc *jobUsecase) RequeueJob(ctx context.Context, id string) error {
+	return uc.jobRepo.RequeueJob(ctx, id)
+}
+
+// RequeueDeadJobs retries every dead job from scratch
+func (uc *jobUsecase) RequeueDeadJobs(ctx context.Context) (int, error) {
+	return uc.jobRepo.RequeueDeadJobs(ctx)
+}
+
+// GetRefreshStats reports how many popularity refreshes were asked for, merged and done
+func (uc *jobUsecase) GetRefreshStats() domain.DispatcherStats {
+	return uc.dispatcher.Stats()
+}
@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// validReportReasons are the categories a reader can pick when reporting content
+var validReportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate_speech":    true,
+	"sexual_content": true,
+	"violence":       true,
+	"misinformation": true,
+	"other":          true,
+}
+
+type reportUsecase struct {
+	reportRepo     domain.ReportRepository
+	blogRepo       domain.BlogRepository
+	commentRepo    domain.CommentRepository
+	userRepo       domain.IUserRepository
+	blogUsecase    domain.BlogUsecase
+	commentUsecase domain.CommentUsecase
+	dispatcher     domain.BlogRefreshDispatcher
+	hideThreshold  int // open reports after which a blog or comment is hidden until reviewed
+}
+
+func NewReportUsecase(
+	reportRepo domain.ReportRepository,
+	blogRepo domain.BlogRepository,
+	commentRepo domain.CommentRepository,
+	userRepo domain.IUserRepository,
+	blogUsecase domain.BlogUsecase,
+	commentUsecase domain.CommentUsecase,
+	dispatcher domain.BlogRefreshDispatcher,
+	hideThreshold int,
+) domain.ReportUsecase {
+	return &reportUsecase{
+		reportRepo:     reportRepo,
+		blogRepo:       blogRepo,
+		commentRepo:    commentRepo,
+		userRepo:       userRepo,
+		blogUsecase:    blogUsecase,
+		commentUsecase: commentUsecase,
+		dispatcher:     dispatcher,
+		hideThreshold:  hideThreshold,
+	}
+}
+
+func (uc *reportUsecase) ReportBlog(ctx context.Context, reporterID string, blogID string, input domain.ReportInput) (*domain.Report, error) {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil || (blog.Status != "" && blog.Status != domain.BlogStatusPublished) {
+		return nil, errors.New("blog not found")
+	}
+	if blog.UserID.Hex() == reporterID {
+		return nil, errors.New("cannot report your own content")
+	}
+
+	report := domain.Report{
+		TargetType: domain.ReportTargetBlog,
+		TargetID:   blog.ID,
+	}
+	return uc.fileReport(ctx, reporterID, report, input)
+}
+
+func (uc *reportUsecase) ReportComment(ctx context.Context, reporterID string, blogID string, commentID string, input domain.ReportInput) (*domain.Report, error) {
+	comment, err := uc.commentRepo.GetCommentByID(ctx, blogID, commentID)
+	if err != nil || comment.Deleted || !comment.IsApproved() {
+		return nil, errors.New("comment not found")
+	}
+	if comment.UserID.Hex() == reporterID {
+		return nil, errors.New("cannot report your own content")
+	}
+
+	report := domain.Report{
+		TargetType: domain.ReportTargetComment,
+		TargetID:   comment.ID,
+		BlogID:     &comment.BlogID,
+	}
+	return uc.fileReport(ctx, reporterID, report, input)
+}
+
+func (uc *reportUsecase) ReportUser(ctx context.Context, reporterID string, userID string, input domain.ReportInput) (*domain.Report, error) {
+	if userID == reporterID {
+		return nil, errors.New("cannot report your own content")
+	}
+	user, err := uc.userRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	report := domain.Report{
+		TargetType: domain.ReportTargetUser,
+		TargetID:   user.ID,
+	}
+	return uc.fileReport(ctx, reporterID, report, input)
+}
+
+// fileReport stores the report and hides the reported blog or comment
+// once it has collected enough open reports
+func (uc *reportUsecase) fileReport(ctx context.Context, reporterID string, report domain.Report, input domain.ReportInput) (*domain.Report, error) {
+	if !validReportReasons[input.Reason] {
+		return nil, errors.New("invalid report reason")
+	}
+
+	reporterObjID, err := primitive.ObjectIDFromHex(reporterID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	report.ReporterID = reporterObjID
+	report.Reason = input.Reason
+	report.Details = input.Details
+	report.Status = domain.ReportStatusOpen
+	report.Created = time.Now()
+
+	created, err := uc.reportRepo.CreateReport(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.TargetType == domain.ReportTargetUser {
+		return created, nil
+	}
+
+	targetID := report.TargetID.Hex()
+	count, err := uc.reportRepo.CountOpenReports(ctx, report.TargetType, targetID)
+	if err != nil {
+		log.Printf("failed to count reports for %s %s: %v", report.TargetType, targetID, err)
+		return created, nil
+	}
+	if count >= uc.hideThreshold {
+		if err := uc.setHidden(ctx, report.TargetType, targetID, report.BlogID, true); err != nil {
+			log.Printf("failed to hide reported %s %s: %v", report.TargetType, targetID, err)
+		}
+	}
+
+	return created, nil
+}
+
+func (uc *reportUsecase) setHidden(ctx context.Context, targetType string, targetID string, blogID *primitive.ObjectID, hidden bool) error {
+	switch targetType {
+	case domain.ReportTargetBlog:
+		return uc.blogRepo.SetBlogHidden(ctx, targetID, hidden)
+	case domain.ReportTargetComment:
+		if blogID == nil {
+			return errors.New("comment not found")
+		}
+		if err := uc.commentRepo.SetCommentHidden(ctx, blogID.Hex(), targetID, hidden); err != nil {
+			return err
+		}
+		// hidden comments are left out of the blog's comment count
+		uc.dispatcher.Enqueue(blogID.Hex())
+	}
+	return nil
+}
+
+func (uc *reportUsecase) GetReportSummaries(ctx context.Context, targetType string, status string, page int, limit int) ([]domain.ReportSummary, int, error) {
+	if targetType != "" && !validReportTarget(targetType) {
+		return nil, 0, errors.New("invalid target type")
+	}
+	if status == "" {
+		status = domain.ReportStatusOpen
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	summaries, total, err := uc.reportRepo.GetReportSummaries(ctx, targetType, status, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve reports: %w", err)
+	}
+	return summaries, total, nil
+}
+
+func (uc *reportUsecase) GetTargetReports(ctx context.Context, targetType string, targetID string) ([]domain.Report, error) {
+	if !validReportTarget(targetType) {
+		return nil, errors.New("invalid target type")
+	}
+	return uc.reportRepo.GetReportsByTarget(ctx, targetType, targetID)
+}
+
+// ResolveReports applies an admin's decision to a reported target and closes its open reports.
+// dismiss makes hidden content visible again, resolve only closes the reports,
+// hide keeps the content hidden and remove deletes it.
+func (uc *reportUsecase) ResolveReports(ctx context.Context, adminID string, targetType string, targetID string, input domain.ReportResolutionInput) (int, error) {
+	if !validReportTarget(targetType) {
+		return 0, errors.New("invalid target type")
+	}
+	if targetType == domain.ReportTargetUser && (input.Action == "hide" || input.Action == "remove") {
+		return 0, errors.New("action not supported for users")
+	}
+
+	reports, err := uc.reportRepo.GetReportsByTarget(ctx, targetType, targetID)
+	if err != nil {
+		return 0, err
+	}
+	var open *domain.Report
+	for i := range reports {
+		if reports[i].Status == domain.ReportStatusOpen {
+			open = &reports[i]
+			break
+		}
+	}
+	if open == nil {
+		return 0, errors.New("no open reports")
+	}
+
+	status := domain.ReportStatusActioned
+	switch input.Action {
+	case "dismiss":
+		status = domain.ReportStatusDismissed
+		if targetType != domain.ReportTargetUser {
+			err = uc.setHidden(ctx, targetType, targetID, open.BlogID, false)
+		}
+	case "resolve":
+	case "hide":
+		err = uc.setHidden(ctx, targetType, targetID, open.BlogID, true)
+	case "remove":
+		if targetType == domain.ReportTargetBlog {
+			err = uc.blogUsecase.DeleteBlogAsAdmin(ctx, targetID)
+		} else if open.BlogID != nil {
+			err = uc.commentUsecase.DeleteCommentAsAdmin(ctx, open.BlogID.Hex(), targetID)
+		}
+	default:
+		return 0, errors.New("invalid action")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return uc.reportRepo.ResolveReports(ctx, targetType, targetID, status, adminID)
+}
+
+func validReportTarget(targetType string) bool {
+	switch targetType {
+	case domain.ReportTargetBlog, domain.ReportTargetComment, domain.ReportTargetUser:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CurrentRevision can be passed as from/to of a diff to compare against the live blog
+const CurrentRevision = "current"
+
+func (uc *blogUsecase) saveRevision(ctx context.Context, blog *domain.Blog, editorID string) (*domain.BlogRevision, error) {
+	editorObjID, err := primitive.ObjectIDFromHex(editorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	revision := domain.BlogRevision{
+		BlogID:   blog.ID,
+		Title:    blog.Title,
+		Content:  blog.Content,
+		Tags:     blog.Tags,
+		EditedBy: editorObjID,
+		Created:  time.Now(),
+	}
+
+	saved, err := uc.revisionRepo.CreateRevision(ctx, revision)
+	if err != nil {
+		if errors.Is(err, domain.ErrRevisionConflict) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save revision: %w", err)
+	}
+	return saved, nil
+}
+
+func (uc *blogUsecase) GetRevisions(ctx context.Context, blogID string, userID string, page int, limit int) (*domain.PaginatedRevisionResponse, error) {
+	if page < 1 || limit < 1 {
+		return nil, fmt.Errorf("invalid pagination params")
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	if _, err := uc.ownedBlog(ctx, blogID, userID); err != nil {
+		return nil, err
+	}
+
+	revisions, total, err := uc.revisionRepo.GetRevisions(ctx, blogID, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &domain.PaginatedRevisionResponse{
+		Revisions:   revisions,
+		TotalCount:  total,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+	}, nil
+}
+
+func (uc *blogUsecase) GetRevisionDiff(ctx context.Context, blogID string, userID string, from string, to string) (*domain.BlogRevisionDiff, error) {
+	if from == "" || to == "" {
+		return nil, errors.New("from and to revisions are required")
+	}
+
+	blog, err := uc.ownedBlog(ctx, blogID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromTitle, fromContent, err := uc.revisionText(ctx, blog, from)
+	if err != nil {
+		return nil, err
+	}
+	toTitle, toContent, err := uc.revisionText(ctx, blog, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.BlogRevisionDiff{
+		From:    from,
+		To:      to,
+		Title:   DiffLines(fromTitle, toTitle),
+		Content: DiffLines(fromContent, toContent),
+	}, nil
+}
+
+func (uc *blogUsecase) RestoreRevision(ctx context.Context, blogID string, userID string, revisionID string) error {
+	if _, err := uc.ownedBlog(ctx, blogID, userID); err != nil {
+		return err
+	}
+
+	revision, err := uc.revisionRepo.GetRevisionByID(ctx, blogID, revisionID)
+	if err != nil {
+		return errors.New("revision not found")
+	}
+
+	// restoring is an ordinary edit, so the version being replaced becomes a revision too
+	return uc.UpdateBlog(ctx, blogID, userID, domain.BlogUpdateInput{
+		UserID:  userID,
+		Title:   revision.Title,
+		Content: revision.Content,
+		Tags:    revision.Tags,
+	})
+}
+
+func (uc *blogUsecase) ownedBlog(ctx context.Context, blogID string, userID string) (*domain.Blog, error) {
+	blog, err := uc.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, errors.New("blog not found")
+	}
+
+	if blog.UserID.Hex() != userID {
+		return nil, errors.New("unauthorized access")
+	}
+	return blog, nil
+}
+
+func (uc *blogUsecase) revisionText(ctx context.Context, blog *domain.Blog, revisionID string) (string, string, error) {
+	if revisionID == CurrentRevision {
+		return blog.Title, blog.Content, nil
+	}
+
+	revision, err := uc.revisionRepo.GetRevisionByID(ctx, blog.ID.Hex(), revisionID)
+	if err != nil {
+		return "", "", errors.New("revision not found")
+	}
+	return revision.Title, revision.Content, nil
+}
+
+// maxDiffCells bounds the work of a diff: when the changed parts of both texts have more lines than this
+// multiplied together, the old lines are shown as removed and the new ones as added
+const maxDiffCells = 25_000_000
+
+// DiffLines returns a line-level diff turning a into b, based on the longest common subsequence of lines.
+// The subsequence is found with Hirschberg's algorithm, which only keeps two rows of the table in memory.
+func DiffLines(a, b string) []domain.DiffLine {
+	from := strings.Split(a, "\n")
+	to := strings.Split(b, "\n")
+
+	// lines both texts start or end with are unchanged and need no table at all
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]domain.DiffLine, 0, len(from)+len(to))
+	for _, line := range from[:prefix] {
+		diff = append(diff, domain.DiffLine{Op: " ", Text: line})
+	}
+
+	fromMid, toMid := from[prefix:len(from)-suffix], to[prefix:len(to)-suffix]
+	if len(fromMid)*len(toMid) > maxDiffCells {
+		diff = appendLines(diff, "-", fromMid)
+		diff = appendLines(diff, "+", toMid)
+	} else {
+		diff = diffRange(diff, fromMid, toMid)
+	}
+
+	for _, line := range from[len(from)-suffix:] {
+		diff = append(diff, domain.DiffLine{Op: " ", Text: line})
+	}
+	return diff
+}
+
+// diffRange appends the diff of from and to, splitting from in half and to where the halves'
+// common subsequences meet, until one side is at most a line long
+func diffRange(diff []domain.DiffLine, from, to []string) []domain.DiffLine {
+	switch {
+	case len(from) == 0:
+		return appendLines(diff, "+", to)
+	case len(to) == 0:
+		return appendLines(diff, "-", from)
+	case len(from) == 1:
+		for j, line := range to {
+			if line == from[0] {
+				diff = appendLines(diff, "+", to[:j])
+				diff = append(diff, domain.DiffLine{Op: " ", Text: line})
+				return appendLines(diff, "+", to[j+1:])
+			}
+		}
+		diff = appendLines(diff, "-", from)
+		return appendLines(diff, "+", to)
+	}
+
+	mid := len(from) / 2
+	head := lcsLengths(from[:mid], to, false)
+	tail := lcsLengths(from[mid:], to, true)
+
+	// to is split where the subsequences of both halves add up to the longest one
+	split := 0
+	for j := range to {
+		if head[j+1]+tail[j+1] > head[split]+tail[split] {
+			split = j + 1
+		}
+	}
+
+	diff = diffRange(diff, from[:mid], to[:split])
+	return diffRange(diff, from[mid:], to[split:])
+}
+
+// lcsLengths returns the length of the longest common subsequence of from and every prefix to[:j];
+// with reverse set it is of every suffix to[j:] instead
+func lcsLengths(from, to []string, reverse bool) []int {
+	at := func(lines []string, i int) string {
+		if reverse {
+			return lines[len(lines)-1-i]
+		}
+		return lines[i]
+	}
+
+	prev := make([]int, len(to)+1)
+	curr := make([]int, len(to)+1)
+	for i := range from {
+		line := at(from, i)
+		for j := range to {
+			if line == at(to, j) {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(prev[j+1], curr[j])
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	if reverse {
+		// prev[k] covers the last k lines of to, which is the suffix starting at len(to)-k
+		slices.Reverse(prev)
+	}
+	return prev
+}
+
+func appendLines(diff []domain.DiffLine, op string, lines []string) []domain.DiffLine {
+	for _, line := range lines {
+		diff = append(diff, domain.DiffLine{Op: op, Text: line})
+	}
+	return diff
+}
@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []domain.DiffLine
+	}{
+		{
+			name: "unchanged",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []domain.DiffLine{{Op: " ", Text: "one"}, {Op: " ", Text: "two"}},
+		},
+		{
+			name: "line replaced",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []domain.DiffLine{{Op: " ", Text: "one"}, {Op: "-", Text: "two"}, {Op: "+", Text: "2"}, {Op: " ", Text: "three"}},
+		},
+		{
+			name: "line added",
+			a:    "one\nthree",
+			b:    "one\ntwo\nthree",
+			want: []domain.DiffLine{{Op: " ", Text: "one"}, {Op: "+", Text: "two"}, {Op: " ", Text: "three"}},
+		},
+		{
+			name: "line removed",
+			a:    "one\ntwo\nthree",
+			b:    "one\nthree",
+			want: []domain.DiffLine{{Op: " ", Text: "one"}, {Op: "-", Text: "two"}, {Op: " ", Text: "three"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffLines(tt.a, tt.b)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DiffLines() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("DiffLines() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestDiffLinesMinimal checks that random diffs turn a into b and keep as many lines as the longest common subsequence
+func TestDiffLinesMinimal(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randomText := func() string {
+		lines := make([]string, rng.IntN(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.IntN(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for range 500 {
+		a, b := randomText(), randomText()
+		diff := DiffLines(a, b)
+
+		var from, to []string
+		kept := 0
+		for _, line := range diff {
+			switch line.Op {
+			case " ":
+				from = append(from, line.Text)
+				to = append(to, line.Text)
+				kept++
+			case "-":
+				from = append(from, line.Text)
+			case "+":
+				to = append(to, line.Text)
+			default:
+				t.Fatalf("unexpected op %q", line.Op)
+			}
+		}
+
+		if strings.Join(from, "\n") != a || strings.Join(to, "\n") != b {
+			t.Fatalf("diff of %q and %q does not reproduce them: %v", a, b, diff)
+		}
+		if want := lcsLength(strings.Split(a, "\n"), strings.Split(b, "\n")); kept != want {
+			t.Fatalf("diff of %q and %q keeps %d lines, want %d", a, b, kept, want)
+		}
+	}
+}
+
+func TestDiffLinesLargeInput(t *testing.T) {
+	a := make([]string, 20_000)
+	b := make([]string, 20_000)
+	for i := range a {
+		a[i] = "old line " + string(rune('a'+i%26))
+		b[i] = "new line " + string(rune('a'+i%26))
+	}
+
+	diff := DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+	if len(diff) != len(a)+len(b) {
+		t.Fatalf("len(DiffLines()) = %d, want %d", len(diff), len(a)+len(b))
+	}
+}
+
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				table[i+1][j+1] = table[i][j] + 1
+			} else {
+				table[i+1][j+1] = max(table[i][j+1], table[i+1][j])
+			}
+		}
+	}
+	return table[len(a)][len(b)]
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+// Scoring strategies
+const (
+	ScoringLinear = "linear" // weighted sum of lifetime activity
+	ScoringWilson = "wilson" // confidence that readers like the blog, from its likes and dislikes
+	ScoringDecay  = "decay"  // weighted activity divided by the blog's age, Hacker News style
+)
+
+// ScoringWeights is what one of each kind of activity adds to a weighted score; dislikes are subtracted
+type ScoringWeights struct {
+	UniqueViews float64
+	Likes       float64
+	Dislikes    float64
+	Comments    float64
+}
+
+// NewScoringStrategy returns the named strategy; reactions count by their weight in the linear and decay strategies
+func NewScoringStrategy(name string, weights ScoringWeights, reactions ReactionPolicy, gravity float64) (domain.ScoringStrategy, error) {
+	linear := &LinearScoring{Weights: weights, ReactionWeights: reactions.weights()}
+
+	switch name {
+	case ScoringLinear:
+		return linear, nil
+	case ScoringWilson:
+		return &WilsonScoring{}, nil
+	case ScoringDecay:
+		return &DecayScoring{Points: linear, Gravity: gravity}, nil
+	default:
+		return nil, fmt.Errorf("unknown scoring strategy %q", name)
+	}
+}
+
+// LinearScoring adds up a blog's lifetime activity; reaction types that are no longer allowed are ignored
+type LinearScoring struct {
+	Weights         ScoringWeights
+	ReactionWeights map[string]float64
+}
+
+func (s *LinearScoring) Score(input domain.ScoreInput, now time.Time) float64 {
+	score := float64(input.UniqueViews)*s.Weights.UniqueViews +
+		float64(input.Likes)*s.Weights.Likes -
+		float64(input.Dislikes)*s.Weights.Dislikes +
+		float64(input.Comments)*s.Weights.Comments
+	for t, count := range input.Reactions {
+		score += float64(count) * s.ReactionWeights[t]
+	}
+	return score
+}
+
+// wilsonZ is the z-score of the 95% confidence level
+const wilsonZ = 1.96
+
+// WilsonScoring scores a blog by the lower bound of the Wilson interval of its like ratio,
+// so a few likes without dislikes do not outrank many likes with some dislikes. Blogs without votes score 0.
+type WilsonScoring struct{}
+
+func (s *WilsonScoring) Score(input domain.ScoreInput, now time.Time) float64 {
+	n := float64(input.Likes + input.Dislikes)
+	if n == 0 {
+		return 0
+	}
+
+	p := float64(input.Likes) / n
+	z2 := wilsonZ * wilsonZ
+	return (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// DecayScoring divides the points of a blog by (age in hours + 2)^gravity, so newer blogs rise above older ones
+// with the same activity. Scores only change when they are recomputed, so they go stale for blogs without new activity.
+type DecayScoring struct {
+	Points  domain.ScoringStrategy
+	Gravity float64
+}
+
+func (s *DecayScoring) Score(input domain.ScoreInput, now time.Time) float64 {
+	age := math.Max(now.Sub(input.Published).Hours(), 0)
+	return s.Points.Score(input, now) / math.Pow(age+2, s.Gravity)
+}
@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestLinearScoring(t *testing.T) {
+	scoring := &LinearScoring{
+		Weights:         ScoringWeights{UniqueViews: 0.1, Likes: 2, Dislikes: 1, Comments: 3},
+		ReactionWeights: map[string]float64{"love": 2, "laugh": 1},
+	}
+
+	input := domain.ScoreInput{
+		UniqueViews: 50,
+		Likes:       4,
+		Dislikes:    2,
+		Comments:    1,
+		Reactions:   map[string]int{"love": 2, "laugh": 1, "removed": 10},
+	}
+
+	// 50*0.1 + 4*2 - 2*1 + 1*3 + 2*2 + 1*1; reactions that are no longer allowed count for nothing
+	if got, want := scoring.Score(input, time.Now()), 19.0; !almostEqual(got, want) {
+		t.Fatalf("Score() = %v, want %v", got, want)
+	}
+}
+
+func TestWilsonScoring(t *testing.T) {
+	scoring := &WilsonScoring{}
+	now := time.Now()
+
+	if got := scoring.Score(domain.ScoreInput{}, now); got != 0 {
+		t.Fatalf("Score() without votes = %v, want 0", got)
+	}
+
+	// one like: (1 + z²/2 - z·sqrt(z²/4)) / (1 + z²) = 1 / (1 + z²)
+	if got, want := scoring.Score(domain.ScoreInput{Likes: 1}, now), 1/(1+wilsonZ*wilsonZ); !almostEqual(got, want) {
+		t.Fatalf("Score() of one like = %v, want %v", got, want)
+	}
+
+	few := scoring.Score(domain.ScoreInput{Likes: 2}, now)
+	many := scoring.Score(domain.ScoreInput{Likes: 90, Dislikes: 10}, now)
+	if few >= many {
+		t.Fatalf("2 likes score %v, want less than 90 likes and 10 dislikes at %v", few, many)
+	}
+
+	if got := scoring.Score(domain.ScoreInput{Dislikes: 5}, now); !almostEqual(got, 0) {
+		t.Fatalf("Score() of only dislikes = %v, want 0", got)
+	}
+}
+
+func TestDecayScoring(t *testing.T) {
+	now := time.Now()
+	scoring := &DecayScoring{
+		Points:  &LinearScoring{Weights: ScoringWeights{Likes: 1}},
+		Gravity: 1.8,
+	}
+
+	fresh := domain.ScoreInput{Likes: 10, Published: now}
+	if got, want := scoring.Score(fresh, now), 10/math.Pow(2, 1.8); !almostEqual(got, want) {
+		t.Fatalf("Score() of a new blog = %v, want %v", got, want)
+	}
+
+	dayOld := domain.ScoreInput{Likes: 10, Published: now.Add(-24 * time.Hour)}
+	if got, want := scoring.Score(dayOld, now), 10/math.Pow(26, 1.8); !almostEqual(got, want) {
+		t.Fatalf("Score() of a day old blog = %v, want %v", got, want)
+	}
+
+	// a publish time ahead of the clock is treated as brand new instead of boosting the score
+	future := domain.ScoreInput{Likes: 10, Published: now.Add(time.Hour)}
+	if got, want := scoring.Score(future, now), scoring.Score(fresh, now); !almostEqual(got, want) {
+		t.Fatalf("Score() of a blog published in the future = %v, want %v", got, want)
+	}
+}
+
+func TestNewScoringStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantType string
+	}{
+		{name: ScoringLinear, wantType: "*usecases.LinearScoring"},
+		{name: ScoringWilson, wantType: "*usecases.WilsonScoring"},
+		{name: ScoringDecay, wantType: "*usecases.DecayScoring"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewScoringStrategy(tt.name, ScoringWeights{}, ReactionPolicy{}, 1.8)
+			if err != nil {
+				t.Fatalf("NewScoringStrategy(%q) returned %v", tt.name, err)
+			}
+			if gotType := fmt.Sprintf("%T", got); gotType != tt.wantType {
+				t.Fatalf("NewScoringStrategy(%q) = %s, want %s", tt.name, gotType, tt.wantType)
+			}
+		})
+	}
+
+	if _, err := NewScoringStrategy("unknown", ScoringWeights{}, ReactionPolicy{}, 1.8); err == nil {
+		t.Fatal("NewScoringStrategy() of an unknown strategy returned no error")
+	}
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	domain "github.com/gedyzed/blog-starter-project/Domain"
+)
+
+// SitemapMaxURLs is the most URLs a single sitemap file may hold, per the sitemap protocol
+const SitemapMaxURLs = 50000
+
+const sitemapCacheKey = "sitemap:urls"
+
+type sitemapUsecase struct {
+	blogRepo     domain.BlogRepository
+	sitemapCache domain.SortedCache[[]domain.SitemapURL]
+	baseURL      string
+}
+
+func NewSitemapUsecase(blogRepo domain.BlogRepository, sitemapCache domain.SortedCache[[]domain.SitemapURL], baseURL string) domain.SitemapUsecase {
+	return &sitemapUsecase{
+		blogRepo:     blogRepo,
+		sitemapCache: sitemapCache,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// GetSitemapURLs lists every published blog, tag page and author profile.
+// The list is built on first use and cached until a blog is created, updated or deleted.
+func (uc *sitemapUsecase) GetSitemapURLs(ctx context.Context) ([]domain.SitemapURL, error) {
+	if cached, found := uc.sitemapCache.Get(sitemapCacheKey); found {
+		log.Println("cache hit for sitemap")
+		return cached, nil
+	}
+
+	blogs, err := uc.blogRepo.GetSitemapBlogs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blogs: %w", err)
+	}
+	tags, err := uc.blogRepo.GetTagsLastUpdated(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	authors, err := uc.blogRepo.GetAuthorsLastUpdated(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authors: %w", err)
+	}
+
+	urls := make([]domain.SitemapURL, 0, len(blogs)+len(tags)+len(authors)+1)
+
+	home := domain.SitemapURL{Loc: uc.baseURL + "/blogs"}
+	if len(blogs) > 0 {
+		// blogs are sorted by their last update, newest first
+		home.LastMod = blogs[0].Updated
+	}
+	urls = append(urls, home)
+
+	for _, blog := range blogs {
+		loc := uc.baseURL + "/blogs/" + blog.ID.Hex()
+		if blog.Slug != "" {
+			loc = uc.baseURL + "/blogs/by-slug/" + url.PathEscape(blog.Slug)
+		}
+		urls = append(urls, domain.SitemapURL{Loc: loc, LastMod: blog.Updated})
+	}
+
+	for _, tag := range tags {
+		urls = append(urls, domain.SitemapURL{
+			Loc:     uc.baseURL + "/blogs/filter?tags=" + url.QueryEscape(tag.Key),
+			LastMod: tag.Updated,
+		})
+	}
+
+	for _, author := range authors {
+		urls = append(urls, domain.SitemapURL{
+			Loc:     uc.baseURL + "/users/" + author.Key + "/profile",
+			LastMod: author.Updated,
+		})
+	}
+
+	uc.sitemapCache.SetWithSortKey(domain.SitemapCacheGroup, sitemapCacheKey, urls)
+	return urls, nil
+}
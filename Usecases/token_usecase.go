@@ -33,13 +33,19 @@ type ITokenUsecase interface {
 	GenerateSecureToken(string) (string, error)
 	VerifyCode(ctx context.Context, token *domain.VToken)(string, error)
 	DeleteVCode(ctx context.Context, userID string) error
-	FindByUserID(ctx context.Context, userID string) (*domain.Token, error)
-	RefreshTokens(ctx context.Context, refreshToken string) (*domain.Token, error)
-	GenerateTokens(ctx context.Context, userID string) (*domain.Token, error)
-	VerifyAccessToken(string) (string, error)
+	FindByUserID(ctx context.Context, userID string) ([]domain.Token, error)
+	FindByRefreshToken(ctx context.Context, refreshToken string) (*domain.Token, error)
+	RefreshTokens(ctx context.Context, userID, refreshToken string, device domain.Device) (*domain.Token, error)
+	GenerateTokens(ctx context.Context, userID string, device domain.Device) (*domain.Token, error)
+	VerifyAccessToken(ctx context.Context, token string) (string, error)
 	DeleteByUserID(ctx context.Context, email string) error	
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeSessions(ctx context.Context, userID, keepSessionID string) (int, error)
+	RevokeAccessTokens(ctx context.Context, userID string) error
+	JWKS(ctx context.Context) (*domain.JWKS, error)
+	RotateSigningKey(ctx context.Context) (*domain.SigningKey, error)
 	SaveToken(ctx context.Context, token *domain.Token) error
-	GetByAccessToken(ctx context.Context, accessToken string)(string, error)
+	GetByAccessToken(ctx context.Context, accessToken string)(*domain.Token, error)
 	
 
 }
@@ -151,20 +157,24 @@ func (t *tokenUsecase) DeleteVCode(ctx context.Context, userID string) error {
 	return t.vtokenRepo.DeleteVCode(ctx, userID)
 }
 
-func (t *tokenUsecase) FindByUserID(ctx context.Context, userID string) (*domain.Token, error) {
+func (t *tokenUsecase) FindByUserID(ctx context.Context, userID string) ([]domain.Token, error) {
 	return t.tokenRepo.FindByUserID(ctx, userID)
 }
 
-func (t *tokenUsecase) RefreshTokens(ctx context.Context, refreshToken string) (*domain.Token, error) {
-	return t.tokenService.RefreshTokens(ctx, refreshToken)
+func (t *tokenUsecase) FindByRefreshToken(ctx context.Context, refreshToken string) (*domain.Token, error) {
+	return t.tokenRepo.FindByRefreshToken(ctx, refreshToken)
 }
 
-func (t *tokenUsecase) GenerateTokens(ctx context.Context, userID string) (*domain.Token, error) {
-	return t.tokenService.GenerateTokens(ctx, userID)
+func (t *tokenUsecase) RefreshTokens(ctx context.Context, userID, refreshToken string, device domain.Device) (*domain.Token, error) {
+	return t.tokenService.RefreshTokens(ctx, userID, refreshToken, device)
 }
 
-func (t *tokenUsecase) VerifyAccessToken(tokenString string) (string, error) {
-	return t.tokenService.VerifyAccessToken(tokenString)
+func (t *tokenUsecase) GenerateTokens(ctx context.Context, userID string, device domain.Device) (*domain.Token, error) {
+	return t.tokenService.GenerateTokens(ctx, userID, device)
+}
+
+func (t *tokenUsecase) VerifyAccessToken(ctx context.Context, tokenString string) (string, error) {
+	return t.tokenService.VerifyAccessToken(ctx, tokenString)
 }
 
 func (t *tokenUsecase) SaveToken(ctx context.Context, tokens *domain.Token) error{
@@ -175,7 +185,27 @@ func (t *tokenUsecase) DeleteByUserID(ctx context.Context, userID string) error
 	return t.tokenRepo.DeleteByUserID(ctx, userID)
 }
 
-func (t *tokenUsecase) GetByAccessToken(ctx context.Context, accessToken string)(string, error){
+func (t *tokenUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	return t.tokenService.RevokeSession(ctx, userID, sessionID)
+}
+
+func (t *tokenUsecase) RevokeSessions(ctx context.Context, userID, keepSessionID string) (int, error) {
+	return t.tokenService.RevokeSessions(ctx, userID, keepSessionID)
+}
+
+func (t *tokenUsecase) RevokeAccessTokens(ctx context.Context, userID string) error {
+	return t.tokenService.RevokeAccessTokens(ctx, userID)
+}
+
+func (t *tokenUsecase) JWKS(ctx context.Context) (*domain.JWKS, error) {
+	return t.tokenService.JWKS(ctx)
+}
+
+func (t *tokenUsecase) RotateSigningKey(ctx context.Context) (*domain.SigningKey, error) {
+	return t.tokenService.RotateSigningKey(ctx)
+}
+
+func (t *tokenUsecase) GetByAccessToken(ctx context.Context, accessToken string)(*domain.Token, error){
 	return t.tokenRepo.FindByAccessToken(ctx, accessToken)
 
 
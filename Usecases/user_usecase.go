@@ -16,28 +16,36 @@ var (
 
 	// Refresh token errors
 	ErrInvalidRefreshToken = errors.New("invalid refresh token")
-	ErrExpiredRefreshToken = errors.New("refresh token has expired")
+	ErrExpiredRefreshToken = domain.ErrExpiredRefreshToken
 
 	// input errors
 	ErrInvalidCredential = errors.New("invalid username or password")
 	ErrUserNotFound      = errors.New("user not found")
 )
 
+// securityEventsLimit is how many of their latest security events a user can see
+const securityEventsLimit = 50
+
 type UserUsecases struct {
 	userRepo        domain.IUserRepository
+	followRepo      domain.IFollowRepository
+	eventRepo       domain.ISecurityEventRepo
 	tokenUsecase    ITokenUsecase
 	passwordService domain.IPasswordService
 }
 
-func NewUserUsecase(userRepo domain.IUserRepository, tu ITokenUsecase, ps domain.IPasswordService) *UserUsecases {
+func NewUserUsecase(userRepo domain.IUserRepository, followRepo domain.IFollowRepository, eventRepo domain.ISecurityEventRepo, tu ITokenUsecase, ps domain.IPasswordService) *UserUsecases {
 	return &UserUsecases{
 		userRepo:        userRepo,
+		followRepo:      followRepo,
+		eventRepo:       eventRepo,
 		tokenUsecase:    tu,
 		passwordService: ps,
 	}
 }
 
-func (u *UserUsecases) Logout(ctx context.Context, username string) error {
+// Logout ends the session the request was made from and revokes its access token; other devices stay signed in
+func (u *UserUsecases) Logout(ctx context.Context, username, userID, sessionID string) error {
 	data, err := u.userRepo.GetByUsername(ctx, username)
 	if err != nil {
 		switch err {
@@ -49,11 +57,11 @@ func (u *UserUsecases) Logout(ctx context.Context, username string) error {
 		}
 	}
 
-	if username != data.Username {
+	if username != data.Username || data.ID.Hex() != userID {
 		return ErrInvalidCredential
 	}
 
-	err = u.tokenUsecase.DeleteByUserID(ctx, data.ID.Hex())
+	err = u.tokenUsecase.RevokeSession(ctx, userID, sessionID)
 	if err != nil {
 		return err
 	}
@@ -61,7 +69,8 @@ func (u *UserUsecases) Logout(ctx context.Context, username string) error {
 	return nil
 }
 
-func (u *UserUsecases) Login(ctx context.Context, user domain.User) (*domain.Token, error) {
+// Login starts a new session for the device; sessions on other devices are kept
+func (u *UserUsecases) Login(ctx context.Context, user domain.User, device domain.Device) (*domain.Token, error) {
 	data, err := u.userRepo.GetByUsername(ctx, user.Username)
 
 	if err != nil {
@@ -81,8 +90,8 @@ func (u *UserUsecases) Login(ctx context.Context, user domain.User) (*domain.Tok
 		return nil, ErrInvalidCredential
 	}
 
-	id := user.ID.Hex()
-	token, err := u.tokenUsecase.GenerateTokens(ctx, id)
+	id := data.ID.Hex()
+	token, err := u.tokenUsecase.GenerateTokens(ctx, id, device)
 	if err != nil {
 		log.Println(err.Error())
 		return nil, domain.ErrInternalServer
@@ -92,7 +101,7 @@ func (u *UserUsecases) Login(ctx context.Context, user domain.User) (*domain.Tok
 }
 
 func (u *UserUsecases) Authenticate(ctx context.Context, token string) (*domain.User, error) {
-	userID, err := u.tokenUsecase.VerifyAccessToken(token)
+	userID, err := u.tokenUsecase.VerifyAccessToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
@@ -109,27 +118,9 @@ func (u *UserUsecases) Authenticate(ctx context.Context, token string) (*domain.
 	return user, nil
 }
 
-func (u *UserUsecases) RefreshToken(ctx context.Context, id string, refreshToken string) (*domain.Token, error) {
-
-	token, err := u.tokenUsecase.FindByUserID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	if token.RefreshToken != refreshToken {
-		return nil, domain.ErrInvalidToken
-	}
-
-	if time.Now().Unix() > token.RefreshExpiry.Unix() {
-		err = u.tokenUsecase.DeleteByUserID(ctx, id)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, ErrExpiredRefreshToken
-	}
-
-	return u.tokenUsecase.RefreshTokens(ctx, refreshToken)
+// RefreshToken exchanges the refresh token of one of the user's sessions for new tokens; see ITokenService for reuse detection
+func (u *UserUsecases) RefreshToken(ctx context.Context, id string, refreshToken string, device domain.Device) (*domain.Token, error) {
+	return u.tokenUsecase.RefreshTokens(ctx, id, refreshToken, device)
 }
 
 func (u *UserUsecases) Register(ctx context.Context, user *domain.User) (string, error) {
@@ -206,14 +197,28 @@ func (u *UserUsecases) ForgotPassword(ctx context.Context, email string) error {
 	return u.tokenUsecase.CreateSendVCode(ctx, email, Password_Reset)
 }
 
+// ResetPassword sets a new password and signs the user out of every device
 func (u *UserUsecases) ResetPassword(ctx context.Context, email string, password string) error {
 
-	password, err := u.passwordService.Hash(password)
+	user, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	password, err = u.passwordService.Hash(password)
 	if err != nil {
 		return domain.ErrInternalServer
 	}
 
-	return u.userRepo.Update(ctx, "email", email, &domain.User{Password: password})
+	if err := u.userRepo.Update(ctx, "email", email, &domain.User{Password: password}); err != nil {
+		return err
+	}
+
+	if _, err := u.tokenUsecase.RevokeSessions(ctx, user.ID.Hex(), ""); err != nil {
+		log.Println(err.Error())
+		return domain.ErrInternalServer
+	}
+	return nil
 }
 
 func (u *UserUsecases) PromoteDemote(ctx context.Context, userID string) error {
@@ -235,7 +240,16 @@ func (u *UserUsecases) PromoteDemote(ctx context.Context, userID string) error {
 		user.Role = "admin"
 	}
 
-	return u.userRepo.Update(ctx, "_id", userID, user)
+	if err := u.userRepo.Update(ctx, "_id", userID, user); err != nil {
+		return err
+	}
+
+	// the user's sessions stay, but their access tokens have to be refreshed
+	if err := u.tokenUsecase.RevokeAccessTokens(ctx, userID); err != nil {
+		log.Println(err.Error())
+		return domain.ErrInternalServer
+	}
+	return nil
 }
 
 func (u *UserUsecases) ProfileUpdate(ctx context.Context, profileUpdate *domain.ProfileUpdateInput) error {
@@ -278,10 +292,83 @@ func (u *UserUsecases) GetByEmail(ctx context.Context, email string) (*domain.Us
 	return u.userRepo.GetByEmail(ctx, email)
 }
 
-func (u *UserUsecases) GetToken(ctx context.Context, accessToken string) (string, error) {
+func (u *UserUsecases) GetToken(ctx context.Context, accessToken string) (*domain.Token, error) {
 	return u.tokenUsecase.GetByAccessToken(ctx, accessToken)
 }
 
+func (u *UserUsecases) GetTokenByRefreshToken(ctx context.Context, refreshToken string) (*domain.Token, error) {
+	return u.tokenUsecase.FindByRefreshToken(ctx, refreshToken)
+}
+
+// GetSessions lists the devices the user is signed in on, marking the one the request was made from
+func (u *UserUsecases) GetSessions(ctx context.Context, userID, currentSessionID string) ([]domain.Session, error) {
+	tokens, err := u.tokenUsecase.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]domain.Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, domain.Session{
+			ID:         token.ID.Hex(),
+			UserAgent:  token.UserAgent,
+			IP:         token.IP,
+			CreatedAt:  token.CreatedAt,
+			LastUsedAt: token.LastUsedAt,
+			Current:    token.ID.Hex() == currentSessionID,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs the user out of one of their devices
+func (u *UserUsecases) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	return u.tokenUsecase.RevokeSession(ctx, userID, sessionID)
+}
+
+// GetSecurityEvents returns the latest security events of the user
+func (u *UserUsecases) GetSecurityEvents(ctx context.Context, userID string) ([]domain.SecurityEvent, error) {
+	return u.eventRepo.FindByUserID(ctx, userID, securityEventsLimit)
+}
+
+// RevokeOtherSessions signs the user out of every device but the one the request was made from
+func (u *UserUsecases) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error) {
+	if currentSessionID == "" {
+		return 0, domain.ErrTokenNotFound
+	}
+	return u.tokenUsecase.RevokeSessions(ctx, userID, currentSessionID)
+}
+
+func (u *UserUsecases) GetPublicProfile(ctx context.Context, userID string) (*domain.PublicProfile, error) {
+	user, err := u.userRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := u.followRepo.CountFollowers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := u.followRepo.CountFollowing(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PublicProfile{
+		ID:             user.ID.Hex(),
+		Username:       user.Username,
+		Firstname:      user.Firstname,
+		Lastname:       user.Lastname,
+		Bio:            user.Profile.Bio,
+		ProfilePic:     user.Profile.ProfilePic,
+		Location:       user.Profile.ContactInfo.Location,
+		JoinedAt:       user.CreatedAt,
+		FollowersCount: followers,
+		FollowingCount: following,
+	}, nil
+}
+
 func (u *UserUsecases) FindByUserID(ctx context.Context, userID string) (*domain.User, error) {
 	return u.userRepo.Get(ctx, userID)
 }